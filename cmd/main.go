@@ -1,13 +1,18 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"slices"
 	"strings"
 	"sync/atomic"
 
-	"github.com/akaspb/playfair-cipher/internal/cipher"
-	"github.com/akaspb/playfair-cipher/internal/decipher"
+	"github.com/akaspb/playfair-cipher/internal/cli"
+	"github.com/akaspb/playfair-cipher/internal/config"
 	"github.com/akaspb/playfair-cipher/internal/tab"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -15,51 +20,72 @@ import (
 const (
 	cipherName   = "  Cipher  "
 	decipherName = "   Decipher  "
+	analysisName = "  Analysis  "
+	cribName     = "  Crib  "
 	configName   = "  Settings "
 	aboutName    = "  About  "
 )
 
 func main() {
-	if err := run(); err != nil {
+	if len(os.Args) > 1 && cli.IsCommand(os.Args[1]) {
+		env := cli.Env{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+		if err := cli.Run(env, os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fs := flag.NewFlagSet("playfair", flag.ExitOnError)
+	confFlag := fs.String("config", "", "path to config file (default $"+config.PathEnv+" or user config dir)")
+	fs.Parse(os.Args[1:])
+
+	confFile, err := config.Path(*confFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := run(confFile); err != nil {
 		log.Fatal("Error running program:", err)
 	}
 }
 
-func run() error {
-	configTab := tab.NewConfig()
+func run(confFile string) error {
+	configTab, err := tab.NewConfig(confFile)
+	if err != nil {
+		return err
+	}
 
-	tabNames := []string{cipherName, decipherName, configName, aboutName}
+	tabNames := []string{cipherName, decipherName, analysisName, cribName, configName, aboutName}
 	tabs := map[string]tab.Tab{
 		cipherName:   nil,
 		decipherName: nil,
+		analysisName: nil,
+		cribName:     nil,
 		configName:   configTab,
 		aboutName:    tab.NewAbout(),
 	}
 
-	a := &app{TabNames: tabNames, Tabs: tabs, ActiveTab: 2, ConfigSettled: atomic.Bool{}}
+	a := &app{TabNames: tabNames, Tabs: tabs, ActiveTab: slices.Index(tabNames, configName), ConfigSettled: atomic.Bool{}}
 
 	go func() {
 		for range configTab.Done {
-			cipherService, err := cipher.New(configTab.Grid, configTab.Positions)
+			engine, err := playfair.New(configTab.Config)
 			if err != nil {
 				log.Print(err.Error())
 				continue
 			}
 
-			decipherService, err := decipher.New(configTab.Grid, configTab.Positions)
-			if err != nil {
-				log.Print(err.Error())
-				continue
-			}
-
-			tabs[cipherName] = tab.NewCipher(cipherService, configTab.Separator)
-			tabs[decipherName] = tab.NewDecipher(decipherService, configTab.Separator)
+			tabs[cipherName] = tab.NewCipher(engine)
+			tabs[decipherName] = tab.NewDecipher(engine)
+			tabs[analysisName] = tab.NewAnalysis(engine)
+			tabs[cribName] = tab.NewCrib(engine)
 
 			a.ConfigSettled.Store(true)
 		}
 	}()
 
-	_, err := tea.NewProgram(a, tea.WithAltScreen()).Run()
+	_, err = tea.NewProgram(a, tea.WithAltScreen()).Run()
 
 	close(configTab.Done)
 
@@ -96,9 +122,12 @@ func (a *app) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	}
 
-	a.Tabs[a.TabNames[a.ActiveTab]].Update(msg)
+	// a crib search may finish after another tab is opened
+	if msg, ok := msg.(tab.CribResultMsg); ok {
+		return a, a.Tabs[cribName].Update(msg)
+	}
 
-	return a, nil
+	return a, a.Tabs[a.TabNames[a.ActiveTab]].Update(msg)
 }
 
 func tabBorderWithBottom(left, middle, right string) lipgloss.Border {
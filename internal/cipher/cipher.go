@@ -1,6 +1,7 @@
 package cipher
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strings"
@@ -11,6 +12,8 @@ import (
 type Cipher struct {
 	grid      *[][]rune
 	positions *map[rune]model.Pos
+
+	Rules model.Rules
 }
 
 func New(grid *[][]rune, positions *map[rune]model.Pos) (*Cipher, error) {
@@ -49,108 +52,78 @@ func (c *Cipher) String() string {
 	return sb.String()
 }
 
-func (c *Cipher) Code(text string, separator rune) (string, error) {
+func (c *Cipher) Code(text string, fillers model.Fillers) (string, error) {
 	if c == nil {
-		log.Fatal("*Cipher instance is nil")
+		return "", errors.New("*Cipher instance is nil")
 	}
 
 	if c.grid == nil {
-		log.Fatal("grid==nil")
+		return "", errors.New("grid==nil")
 	}
 
 	if c.positions == nil {
-		log.Fatal("positions==nil")
-	}
-
-	if strings.ContainsRune(text, separator) {
-		return "", fmt.Errorf("[text] must not contain [separator] '%c'", separator)
-	}
-
-	if _, ok := (*c.positions)[separator]; !ok {
-		return "", fmt.Errorf("[separator] '%c' not in grid", separator)
+		return "", errors.New("positions==nil")
 	}
 
-	pairs := getPairs(text, separator)
-	if len(pairs)%2 == 1 {
-		log.Fatal("pairs % 2 == 1")
-	}
-
-	height, width := len(*c.grid), len((*c.grid)[0])
-	cipherPairs := make([]rune, 0, len(pairs))
-	for i := 0; i < len(pairs); i += 2 {
-		char1, char2 := pairs[i], pairs[i+1]
-		pos1, ok := (*c.positions)[char1]
-		if !ok {
-			return "", fmt.Errorf("char '%c' not found in grid", char1)
-		}
-
-		pos2, ok := (*c.positions)[char2]
-		if !ok {
-			return "", fmt.Errorf("char '%c' not found in grid", char2)
-		}
-
-		if pos1 == pos2 {
-			log.Fatal("pos1 == pos2")
+	if fillers != (model.Fillers{}) {
+		for _, filler := range []rune{fillers.Double, fillers.Pad} {
+			if _, ok := (*c.positions)[filler]; !ok {
+				return "", fmt.Errorf("filler '%c' not in grid", filler)
+			}
 		}
-
-		pos1To, pos2To := procPair(pos1, pos2, height, width)
-		char1To := (*c.grid)[pos1To.I()][pos1To.J()]
-		char2To := (*c.grid)[pos2To.I()][pos2To.J()]
-
-		cipherPairs = append(cipherPairs, char1To, char2To)
 	}
 
-	return string(cipherPairs), nil
+	return code(text, fillers, true, c.procChars)
 }
 
-func getPairs(text string, sep rune) []rune {
-	chars := []rune(text)
-
-	res := make([]rune, 0, 2*len(chars))
-	var prevChar rune
-	for _, char := range chars {
-		if len(res)%2 == 0 {
-			res = append(res, char)
-			prevChar = char
-			continue
-		}
-
-		if prevChar == char {
-			res = append(res, sep)
-			// prevChar = 0  не влияет на работу алгоритма
-		}
+func (c *Cipher) procChars(char1, char2 rune) (_, _ rune, _ error) {
+	pos1, ok := (*c.positions)[char1]
+	if !ok {
+		return 0, 0, fmt.Errorf("char '%c' not found in grid", char1)
+	}
 
-		res = append(res, char)
+	pos2, ok := (*c.positions)[char2]
+	if !ok {
+		return 0, 0, fmt.Errorf("char '%c' not found in grid", char2)
 	}
 
-	if len(res)%2 == 1 {
-		res = append(res, sep)
+	if pos1 == pos2 {
+		return 0, 0, fmt.Errorf("pair '%c%c' consists of identical chars", char1, char2)
 	}
 
-	return res
+	height, width := len(*c.grid), len((*c.grid)[0])
+	pos1To, pos2To := procPair(pos1, pos2, height, width, c.Rules)
+
+	return (*c.grid)[pos1To.I()][pos1To.J()], (*c.grid)[pos2To.I()][pos2To.J()], nil
 }
 
-func procPair(p1, p2 model.Pos, height, width int) (_, _ model.Pos) {
+func procPair(p1, p2 model.Pos, height, width int, rules model.Rules) (_, _ model.Pos) {
+	rowShift, columnShift := rules.Shifts()
+
 	switch {
 	case p1.I() == p2.I():
-		return procHorizontal(p1, p2, width)
+		return procHorizontal(p1, p2, width, rowShift)
 	case p1.J() == p2.J():
-		return procVertical(p1, p2, height)
+		return procVertical(p1, p2, height, columnShift)
 	default:
 	}
 
-	return procRectangle(p1, p2)
+	return procRectangle(p1, p2, rules.Rectangle)
 }
 
-func procHorizontal(p1, p2 model.Pos, width int) (_, _ model.Pos) {
-	return model.Pos{p1.I(), (p1.J() + 1) % width}, model.Pos{p2.I(), (p2.J() + 1) % width}
+func procHorizontal(p1, p2 model.Pos, width, shift int) (_, _ model.Pos) {
+	return model.Pos{p1.I(), move(p1.J(), shift, width)}, model.Pos{p2.I(), move(p2.J(), shift, width)}
 }
 
-func procVertical(p1, p2 model.Pos, height int) (_, _ model.Pos) {
-	return model.Pos{(p1.I() + 1) % height, p1.J()}, model.Pos{(p2.I() + 1) % height, p2.J()}
+func procVertical(p1, p2 model.Pos, height, shift int) (_, _ model.Pos) {
+	return model.Pos{move(p1.I(), shift, height), p1.J()}, model.Pos{move(p2.I(), shift, height), p2.J()}
 }
 
-func procRectangle(p1, p2 model.Pos) (_, _ model.Pos) {
+func move(idx, shift, size int) int {
+	return ((idx+shift)%size + size) % size
+}
+
+func procRectangle(p1, p2 model.Pos, rectangle model.Rectangle) (_, _ model.Pos) {
 	var reversed bool
 	if p1.I() > p2.I() {
 		p1, p2 = p2, p1
@@ -158,9 +131,30 @@ func procRectangle(p1, p2 model.Pos) (_, _ model.Pos) {
 	}
 
 	p1To, p2To := model.Pos{p1.I(), p2.J()}, model.Pos{p2.I(), p1.J()}
+	if rectangle == model.SameColumn {
+		p1To, p2To = p2To, p1To
+	}
+
 	if reversed {
 		return p2To, p1To
 	}
 
 	return p1To, p2To
 }
+
+func (c *Cipher) CodePassThrough(text string, fillers model.Fillers) (string, error) {
+	if c == nil {
+		return "", errors.New("*Cipher instance is nil")
+	}
+
+	if c.positions == nil {
+		return "", errors.New("positions==nil")
+	}
+
+	return codePassThrough(text, fillers, pairsOf(true), c.inGrid, c.Code)
+}
+
+func (c *Cipher) inGrid(char rune) bool {
+	_, ok := (*c.positions)[char]
+	return ok
+}
@@ -0,0 +1,140 @@
+package cipher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+// Cube enciphers trigraphs over a keyed cube.
+type Cube struct {
+	cube      *[][][]rune
+	positions *map[rune]model.Pos3
+}
+
+func NewCube(cube *[][][]rune, positions *map[rune]model.Pos3) (*Cube, error) {
+	return &Cube{
+		cube:      cube,
+		positions: positions,
+	}, nil
+}
+
+func (c *Cube) Code(text string, fillers model.Fillers) (string, error) {
+	if c == nil {
+		return "", errors.New("*Cube instance is nil")
+	}
+
+	if c.cube == nil {
+		return "", errors.New("cube==nil")
+	}
+
+	if c.positions == nil {
+		return "", errors.New("positions==nil")
+	}
+
+	if fillers != (model.Fillers{}) {
+		if _, ok := (*c.positions)[fillers.Pad]; !ok {
+			return "", fmt.Errorf("filler '%c' not in grid", fillers.Pad)
+		}
+	}
+
+	trigraphs, _, err := getTrigraphs(text, squareFillers(fillers))
+	if err != nil {
+		return "", err
+	}
+
+	res := make([]rune, 0, len(trigraphs))
+	for i := 0; i < len(trigraphs); i += 3 {
+		char1To, char2To, char3To, err := c.procChars(trigraphs[i], trigraphs[i+1], trigraphs[i+2])
+		if err != nil {
+			return "", err
+		}
+
+		res = append(res, char1To, char2To, char3To)
+	}
+
+	return string(res), nil
+}
+
+func (c *Cube) CodePassThrough(text string, fillers model.Fillers) (string, error) {
+	if c == nil {
+		return "", errors.New("*Cube instance is nil")
+	}
+
+	if c.positions == nil {
+		return "", errors.New("positions==nil")
+	}
+
+	return codePassThrough(text, squareFillers(fillers), getTrigraphs, c.inGrid, c.Code)
+}
+
+func (c *Cube) inGrid(char rune) bool {
+	_, ok := (*c.positions)[char]
+	return ok
+}
+
+// getTrigraphs pads text to whole trigraphs. Repeated chars need no
+// separator as any trigraph, even of one char, is enciphered.
+func getTrigraphs(text string, fillers model.Fillers) (_ []rune, inserted []bool, _ error) {
+	res := []rune(text)
+	inserted = make([]bool, len(res), len(res)+2)
+	if fillers == (model.Fillers{}) {
+		if len(res)%3 != 0 {
+			return nil, nil, errors.New("[text] length must be a multiple of 3 when no fillers are set")
+		}
+
+		return res, inserted, nil
+	}
+
+	for len(res)%3 != 0 {
+		var prevChar rune
+		if len(res) > 0 {
+			prevChar = res[len(res)-1]
+		}
+
+		filler := fillers.PadFor(prevChar)
+		if filler == 0 {
+			return nil, nil, fmt.Errorf("last char '%c' equals the padding filler, alternate filler required", prevChar)
+		}
+
+		res = append(res, filler)
+		inserted = append(inserted, true)
+	}
+
+	return res, inserted, nil
+}
+
+func (c *Cube) procChars(char1, char2, char3 rune) (_, _, _ rune, _ error) {
+	var pos [3]model.Pos3
+	for i, char := range []rune{char1, char2, char3} {
+		p, ok := (*c.positions)[char]
+		if !ok {
+			return 0, 0, 0, fmt.Errorf("char '%c' not found in grid", char)
+		}
+		pos[i] = p
+	}
+
+	depth, height, width := len(*c.cube), len((*c.cube)[0]), len((*c.cube)[0][0])
+	pos1To, pos2To, pos3To := procTrigraph(pos[0], pos[1], pos[2], depth, height, width)
+
+	return c.at(pos1To), c.at(pos2To), c.at(pos3To), nil
+}
+
+func (c *Cube) at(p model.Pos3) rune {
+	return (*c.cube)[p.L()][p.I()][p.J()]
+}
+
+// procTrigraph moves a trigraph of one repeated char diagonally by one cell.
+// Otherwise the chars swap coordinates: each takes its own layer, the row of
+// the next char and the column of the one after it.
+func procTrigraph(p1, p2, p3 model.Pos3, depth, height, width int) (_, _, _ model.Pos3) {
+	if p1 == p2 && p2 == p3 {
+		p := model.Pos3{(p1.L() + 1) % depth, (p1.I() + 1) % height, (p1.J() + 1) % width}
+		return p, p, p
+	}
+
+	return model.Pos3{p1.L(), p2.I(), p3.J()},
+		model.Pos3{p2.L(), p3.I(), p1.J()},
+		model.Pos3{p3.L(), p1.I(), p2.J()}
+}
@@ -0,0 +1,64 @@
+package cipher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+// FourSquare looks plaintext pairs up in the plain squares (top left and
+// bottom right) and takes the ciphertext from the keyed ones (top right and
+// bottom left).
+type FourSquare struct {
+	plain          *[][]rune
+	plainPositions *map[rune]model.Pos
+	keyed          [2]*[][]rune
+}
+
+func NewFourSquare(plain *[][]rune, plainPositions *map[rune]model.Pos, keyed1, keyed2 *[][]rune) (*FourSquare, error) {
+	return &FourSquare{
+		plain:          plain,
+		plainPositions: plainPositions,
+		keyed:          [2]*[][]rune{keyed1, keyed2},
+	}, nil
+}
+
+func (f *FourSquare) Code(text string, fillers model.Fillers) (string, error) {
+	if f == nil {
+		return "", errors.New("*FourSquare instance is nil")
+	}
+
+	if _, ok := (*f.plainPositions)[fillers.Pad]; !ok {
+		return "", fmt.Errorf("filler '%c' not in grid", fillers.Pad)
+	}
+
+	return code(text, squareFillers(fillers), false, f.procChars)
+}
+
+func (f *FourSquare) CodePassThrough(text string, fillers model.Fillers) (string, error) {
+	if f == nil {
+		return "", errors.New("*FourSquare instance is nil")
+	}
+
+	return codePassThrough(text, squareFillers(fillers), pairsOf(false), f.inGrid, f.Code)
+}
+
+func (f *FourSquare) inGrid(char rune) bool {
+	_, ok := (*f.plainPositions)[char]
+	return ok
+}
+
+func (f *FourSquare) procChars(char1, char2 rune) (_, _ rune, _ error) {
+	pos1, ok := (*f.plainPositions)[char1]
+	if !ok {
+		return 0, 0, fmt.Errorf("char '%c' not found in grid", char1)
+	}
+
+	pos2, ok := (*f.plainPositions)[char2]
+	if !ok {
+		return 0, 0, fmt.Errorf("char '%c' not found in grid", char2)
+	}
+
+	return (*f.keyed[0])[pos1.I()][pos2.J()], (*f.keyed[1])[pos2.I()][pos1.J()], nil
+}
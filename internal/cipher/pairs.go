@@ -0,0 +1,158 @@
+package cipher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+type pairProc func(char1, char2 rune) (_, _ rune, _ error)
+
+// groupFunc splits text into the groups a cipher works on, reporting which
+// runes are inserted fillers.
+type groupFunc func(text string, fillers model.Fillers) (_ []rune, inserted []bool, _ error)
+
+func pairsOf(splitDoubles bool) groupFunc {
+	return func(text string, fillers model.Fillers) ([]rune, []bool, error) {
+		return getPairs(text, fillers, splitDoubles)
+	}
+}
+
+func code(text string, fillers model.Fillers, splitDoubles bool, proc pairProc) (string, error) {
+	pairs, _, err := getPairs(text, fillers, splitDoubles)
+	if err != nil {
+		return "", err
+	}
+
+	if len(pairs)%2 == 1 {
+		return "", errors.New("pairs % 2 == 1")
+	}
+
+	cipherPairs := make([]rune, 0, len(pairs))
+	for i := 0; i < len(pairs); i += 2 {
+		char1To, char2To, err := proc(pairs[i], pairs[i+1])
+		if err != nil {
+			return "", err
+		}
+
+		cipherPairs = append(cipherPairs, char1To, char2To)
+	}
+
+	return string(cipherPairs), nil
+}
+
+func codePassThrough(
+	text string,
+	fillers model.Fillers,
+	group groupFunc,
+	inGrid func(rune) bool,
+	codeFn func(string, model.Fillers) (string, error),
+) (string, error) {
+	chars := []rune(text)
+	gridChars := make([]rune, 0, len(chars))
+	for _, char := range chars {
+		if inGrid(char) {
+			gridChars = append(gridChars, char)
+		}
+	}
+
+	if len(gridChars) == 0 {
+		return text, nil
+	}
+
+	ciphered, err := codeFn(string(gridChars), fillers)
+	if err != nil {
+		return "", err
+	}
+
+	_, inserted, err := group(string(gridChars), fillers)
+	if err != nil {
+		return "", err
+	}
+
+	cipherChars := []rune(ciphered)
+	res := make([]rune, 0, len(chars)+len(cipherChars)-len(gridChars))
+	k := 0
+	for _, char := range chars {
+		if !inGrid(char) {
+			res = append(res, char)
+			continue
+		}
+
+		for inserted[k] {
+			res = append(res, cipherChars[k])
+			k++
+		}
+
+		res = append(res, cipherChars[k])
+		k++
+	}
+
+	return string(append(res, cipherChars[k:]...)), nil
+}
+
+// Digraphs splits text into the pairs Code enciphers, inserted reports
+// which runes are fillers.
+func Digraphs(text string, fillers model.Fillers) (_ []rune, inserted []bool, _ error) {
+	return getPairs(text, fillers, true)
+}
+
+// getPairs splits text into pairs inserting fillers. Zero fillers mean text
+// is already split, as between the rounds of a multi-round cipher. A text
+// ending in a filler gets the tail marker before padding.
+func getPairs(text string, fillers model.Fillers, splitDoubles bool) (_ []rune, inserted []bool, _ error) {
+	chars := []rune(text)
+	if fillers == (model.Fillers{}) {
+		if len(chars)%2 == 1 {
+			return nil, nil, errors.New("[text] must be even-length string when no fillers are set")
+		}
+
+		splitDoubles = false
+	}
+
+	marked := len(chars)
+	if marker := fillers.TailMarker(); marker != 0 && len(chars) > 0 {
+		if last := chars[len(chars)-1]; last == fillers.Pad || last == marker {
+			chars = append(chars, marker)
+		}
+	}
+
+	res := make([]rune, 0, 2*len(chars))
+	inserted = make([]bool, 0, 2*len(chars))
+	var prevChar rune
+	for i, char := range chars {
+		if len(res)%2 == 0 {
+			res = append(res, char)
+			inserted = append(inserted, i == marked)
+			prevChar = char
+			continue
+		}
+
+		if splitDoubles && prevChar == char {
+			filler := fillers.DoubleFor(char)
+			if filler == 0 {
+				return nil, nil, fmt.Errorf("doubled '%c' equals the filler, alternate filler required", char)
+			}
+
+			res = append(res, filler)
+			inserted = append(inserted, true)
+			// prevChar = 0  не влияет на работу алгоритма
+		}
+
+		res = append(res, char)
+		inserted = append(inserted, i == marked)
+	}
+
+	if len(res)%2 == 1 {
+		filler := fillers.PadFor(prevChar)
+		if filler == 0 {
+			return nil, nil, fmt.Errorf("last char '%c' equals the padding filler, alternate filler required", prevChar)
+		}
+
+		res = append(res, filler)
+		inserted = append(inserted, true)
+	}
+
+	return res, inserted, nil
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+var ErrUnknownCommand = errors.New("unknown command")
+
+type Env struct {
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+type command struct {
+	usage string
+	run   func(env Env, args []string) error
+}
+
+var commands = map[string]command{}
+
+func register(name, usage string, run func(env Env, args []string) error) {
+	commands[name] = command{usage: usage, run: run}
+}
+
+func IsCommand(name string) bool {
+	_, ok := commands[name]
+	return ok || name == "help"
+}
+
+func Run(env Env, args []string) error {
+	if len(args) == 0 || args[0] == "help" {
+		printUsage(env.Stdout)
+		return nil
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		printUsage(env.Stderr)
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+	}
+
+	err := cmd.run(env, args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return nil
+	}
+
+	return err
+}
+
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sb := strings.Builder{}
+	sb.WriteString("Usage:\n  playfair                  start interactive mode\n")
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("  playfair %-16s %s\n", name, commands[name].usage))
+	}
+
+	fmt.Fprint(w, sb.String())
+}
+
+func newFlagSet(env Env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+
+	return fs
+}
+
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/akaspb/playfair-cipher/internal/crack"
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+func init() {
+	register("crack", "recover the key of a playfair ciphertext by simulated annealing", runCrack)
+}
+
+func runCrack(env Env, args []string) error {
+	var (
+		f       engineFlags
+		lang    ngramFlags
+		in, out string
+		opts    crack.Options
+		seed    uint64
+		timeout time.Duration
+		quiet   bool
+	)
+
+	fs := newFlagSet(env, "crack")
+	fs.StringVar(&in, "in", "", "ciphertext file (default stdin)")
+	fs.StringVar(&out, "out", "", "plaintext output file (default stdout)")
+	fs.IntVar(&opts.Steps, "steps", 0, "temperature steps (default 100)")
+	fs.IntVar(&opts.Iterations, "iterations", 0, "candidates tried at each temperature (default 20000)")
+	fs.IntVar(&opts.Restarts, "restarts", 1, "independent runs, the best one wins")
+	fs.Float64Var(&opts.Temperature, "temperature", 0, "start temperature (default scaled by text length)")
+	fs.Uint64Var(&seed, "seed", 0, "random seed for a repeatable search (default random)")
+	fs.DurationVar(&timeout, "timeout", 0, "stop after this long and report the best key so far")
+	fs.BoolVar(&quiet, "quiet", false, "don't print progress")
+	lang.bind(fs)
+	f.bind(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, _, err := f.baseConfig()
+	if err != nil {
+		return err
+	}
+
+	plain, err := plainEngine("crack", &cfg)
+	if err != nil {
+		return err
+	}
+
+	text, err := readInput(env, in)
+	if err != nil {
+		return err
+	}
+
+	cipherText := plain.GridText(text)
+	if opts.Model, err = lang.model(cfg); err != nil {
+		return err
+	}
+
+	opts.Height, opts.Width, opts.Rules, opts.Seed = cfg.Height, cfg.Width, cfg.Rules, seed
+	if !quiet {
+		opts.Progress = func(p crack.Progress) {
+			fmt.Fprintf(env.Stderr, "\rrun %d/%d  step %3d/%d  temperature %6.2f  score %.3f ",
+				p.Restart, p.Restarts, p.Step, p.Steps, p.Temperature, p.Score)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	res, err := crack.Anneal(ctx, cipherText, opts)
+	if !quiet {
+		fmt.Fprintln(env.Stderr)
+	}
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		fmt.Fprintln(env.Stderr, "stopped early, best key so far:")
+	case err != nil:
+		return err
+	}
+
+	cfg.Key = string(res.Key)
+	engine, err := playfair.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.Stderr, "key:   %s\nscore: %.3f\n%s\n", cfg.Key, res.Score, engine)
+
+	plainText, err := engine.Decrypt(string(cipherText))
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(env, out, plainText)
+}
+
+// plainEngine checks that cfg is a single round playfair and keys it with
+// the alphabet, so that a matrix found by rows can be used as the key.
+// Strict fillers are off: the found key has to decipher the text even when
+// fillers are ambiguous.
+func plainEngine(command string, cfg *model.Config) (*playfair.Engine, error) {
+	if cfg.Algorithm != "" && cfg.Algorithm != model.Playfair || len(cfg.Rounds) > 0 {
+		return nil, fmt.Errorf("%s supports single round playfair only", command)
+	}
+
+	cfg.Key, cfg.Fill, cfg.KDF = string(cfg.Chars), model.FillRows, nil
+	cfg.StrictFillers = false
+
+	return playfair.New(*cfg)
+}
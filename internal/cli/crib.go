@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/akaspb/playfair-cipher/internal/crib"
+)
+
+func init() {
+	register("crib", "reconstruct the matrix from known plaintext or drag a crib", runCrib)
+}
+
+func runCrib(env Env, args []string) error {
+	var (
+		f        engineFlags
+		in       string
+		cribText string
+		offsets  []int
+		opts     crib.Options
+	)
+
+	fs := newFlagSet(env, "crib")
+	fs.StringVar(&in, "in", "", "ciphertext file (default stdin)")
+	fs.StringVar(&cribText, "crib", "", "known plaintext")
+	fs.Func("offset", "ciphertext offset of the crib, repeatable (default every offset)", func(s string) error {
+		offset, err := strconv.Atoi(s)
+		offsets = append(offsets, offset)
+		return err
+	})
+	fs.IntVar(&opts.MaxMatrices, "max", 10, "matrices to list for a single offset")
+	fs.IntVar(&opts.MaxNodes, "nodes", 0, "cells to try per offset before giving up (default 2000000, 100000 when dragging)")
+	f.bind(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cribText == "" {
+		return errors.New("[crib] must be non-empty string")
+	}
+
+	cfg, _, err := f.baseConfig()
+	if err != nil {
+		return err
+	}
+
+	plain, err := plainEngine("crib", &cfg)
+	if err != nil {
+		return err
+	}
+
+	text, err := readInput(env, in)
+	if err != nil {
+		return err
+	}
+
+	cipherText, cribChars := plain.GridText(text), plain.GridText(cribText)
+	opts.Chars, opts.Height, opts.Width, opts.Rules = cfg.Chars, cfg.Height, cfg.Width, cfg.Rules
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if len(offsets) == 1 {
+		digraphs, err := crib.Pairs(cribChars, cipherText, offsets[0], plain.Fillers())
+		if err != nil {
+			return err
+		}
+
+		res, err := crib.Solve(ctx, digraphs, opts)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+
+		switch {
+		case len(res.Matrices) == 0 && res.Complete:
+			fmt.Fprintln(env.Stdout, "no consistent matrix")
+		case res.Complete:
+			fmt.Fprintf(env.Stdout, "%d consistent matrices up to rotation\n", len(res.Matrices))
+		default:
+			fmt.Fprintf(env.Stdout, "%d consistent matrices, search stopped before the end\n", len(res.Matrices))
+		}
+
+		for _, m := range res.Matrices {
+			fmt.Fprintf(env.Stdout, "\n%s\n", m)
+		}
+
+		return nil
+	}
+
+	if len(offsets) == 0 {
+		for offset := range cipherText {
+			offsets = append(offsets, offset)
+		}
+	}
+
+	dragOpts := opts
+	dragOpts.MaxMatrices = 1
+	if dragOpts.MaxNodes == 0 {
+		dragOpts.MaxNodes = 100000
+	}
+
+	var inconsistent int
+	for _, offset := range offsets {
+		digraphs, err := crib.Pairs(cribChars, cipherText, offset, plain.Fillers())
+		if err != nil || len(digraphs) == 0 {
+			continue
+		}
+
+		res, err := crib.Solve(ctx, digraphs, dragOpts)
+		switch {
+		case errors.Is(err, context.Canceled):
+			return err
+		case err != nil:
+			// a doubled pair can't be enciphered, the crib isn't there
+			inconsistent++
+		case len(res.Matrices) > 0:
+			fmt.Fprintf(env.Stdout, "offset %d: consistent\n", offset)
+		case res.Complete:
+			inconsistent++
+		default:
+			fmt.Fprintf(env.Stdout, "offset %d: undecided\n", offset)
+		}
+	}
+
+	fmt.Fprintf(env.Stdout, "%d offsets ruled out, use --offset N to list the matrices of one\n", inconsistent)
+
+	return nil
+}
@@ -0,0 +1,385 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/internal/config"
+	"github.com/akaspb/playfair-cipher/internal/file"
+	"github.com/akaspb/playfair-cipher/internal/keystore"
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+func init() {
+	register("encrypt", "encrypt text from stdin or --in", func(env Env, args []string) error {
+		return runCrypt(env, "encrypt", args, (*playfair.Engine).Encrypt)
+	})
+	register("decrypt", "decrypt text from stdin or --in", func(env Env, args []string) error {
+		return runCrypt(env, "decrypt", args, (*playfair.Engine).Decrypt)
+	})
+}
+
+type cryptFlags struct {
+	in     string
+	out    string
+	report bool
+	engineFlags
+}
+
+type engineFlags struct {
+	configFlag string
+	configPath string
+	profile    string
+	preset     string
+	algorithm  string
+	arrange    string
+	key        string
+	secondKey  string
+	roundKeys  []string
+	roundSizes [][2]int
+	rowShift   int
+	colShift   int
+	rectangle  string
+	fill       string
+	kdf        kdfFlags
+	alphabet   string
+	height     int
+	width      int
+	depth      int
+	separator  string
+	pad        string
+	alt        string
+	passThru   bool
+	strict     bool
+	foldCase   bool
+	stripDiacr bool
+	dropUnkn   bool
+	keystore   keystoreFlags
+}
+
+func (f *engineFlags) bind(fs *flag.FlagSet) {
+	fs.StringVar(&f.configFlag, "config", "", "path to config file (default $"+config.PathEnv+" or user config dir)")
+	fs.StringVar(&f.profile, "profile", "", "named profile (default profile if set)")
+	fs.StringVar(&f.preset, "preset", "", "alphabet preset, see 'playfair presets'")
+	fs.StringVar(&f.algorithm, "algorithm", "", "cipher algorithm: playfair, two-square, four-square or cube")
+	fs.StringVar(&f.arrange, "arrangement", "", "two-square arrangement: horizontal or vertical")
+	fs.StringVar(&f.key, "key", "", "cipher key")
+	fs.StringVar(&f.secondKey, "key2", "", "second cipher key (two-square, four-square)")
+	fs.Func("round-key", "key of an extra playfair round, repeatable", func(s string) error {
+		f.roundKeys = append(f.roundKeys, s)
+		return nil
+	})
+	fs.Func("round-size", "HxW matrix of an extra round in --round-key order, repeatable", func(s string) error {
+		var size [2]int
+		if _, err := fmt.Sscanf(s, "%dx%d", &size[0], &size[1]); err != nil {
+			return fmt.Errorf("size must look like 6x6: %w", err)
+		}
+
+		f.roundSizes = append(f.roundSizes, size)
+		return nil
+	})
+	fs.IntVar(&f.rowShift, "row-shift", 0, "cells a pair in one row moves by, negative to the left (default 1)")
+	fs.IntVar(&f.colShift, "column-shift", 0, "cells a pair in one column moves by, negative upwards (default 1)")
+	fs.StringVar(&f.rectangle, "rectangle", "", "rectangle corner taken: row (default) or column")
+	fs.StringVar(&f.fill, "fill", "", "matrix fill: "+fillNames()+" (default rows)")
+	f.kdf.bind(fs)
+	fs.StringVar(&f.alphabet, "alphabet", "", "matrix alphabet")
+	fs.IntVar(&f.height, "height", 0, "matrix height")
+	fs.IntVar(&f.width, "width", 0, "matrix width")
+	fs.IntVar(&f.depth, "depth", 0, "cube depth (cube)")
+	fs.StringVar(&f.separator, "separator", "", "separator character")
+	fs.StringVar(&f.pad, "pad", "", "padding filler (default separator)")
+	fs.StringVar(&f.alt, "alt", "", "alternate filler used when a filler would be doubled")
+	fs.BoolVar(&f.passThru, "passthrough", false, "keep characters missing from the matrix unchanged")
+	fs.BoolVar(&f.strict, "strict", false, "report ambiguous fillers instead of removing them")
+	fs.BoolVar(&f.foldCase, "fold-case", false, "map letters to the case present in the matrix")
+	fs.BoolVar(&f.stripDiacr, "strip-diacritics", false, "remove diacritics from letters missing from the matrix")
+	fs.BoolVar(&f.dropUnkn, "drop-unknown", false, "drop characters missing from the matrix")
+	f.keystore.bind(fs)
+}
+
+func (f *engineFlags) config(env Env) (model.Config, error) {
+	cfg, profile, err := f.baseConfig()
+	if err != nil {
+		return model.Config{}, err
+	}
+
+	needSecond := cfg.Algorithm.TwoKeys() && cfg.SecondKey == ""
+	needRounds := false
+	for _, r := range cfg.Rounds {
+		needRounds = needRounds || r.Key == ""
+	}
+
+	if (cfg.Key == "" || needSecond || needRounds) && keystore.Exists(f.keystore.keystorePath(f.configPath)) {
+		store, err := f.keystore.open(env, f.configPath)
+		if err != nil {
+			return model.Config{}, err
+		}
+
+		if cfg.Key == "" {
+			cfg.Key, _ = store.Get(keyName(profile))
+		}
+
+		if needSecond {
+			cfg.SecondKey, _ = store.Get(keystore.SecondName(keyName(profile)))
+		}
+
+		for i := range cfg.Rounds {
+			if cfg.Rounds[i].Key == "" {
+				cfg.Rounds[i].Key, _ = store.Get(keystore.RoundName(keyName(profile), i+2))
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+func (f *engineFlags) resolve() (err error) {
+	f.configPath, err = config.Path(f.configFlag)
+	return err
+}
+
+func (f *engineFlags) baseConfig() (cfg model.Config, profile string, err error) {
+	if err = f.resolve(); err != nil {
+		return model.Config{}, "", err
+	}
+
+	profile, err = f.resolveProfile()
+	if err != nil {
+		return model.Config{}, "", err
+	}
+
+	switch {
+	case f.preset != "":
+		var preset config.Preset
+		if preset, err = config.LookupPreset(f.preset); err == nil {
+			cfg = preset.Config()
+		}
+	case profile != "":
+		cfg, err = config.NewProfiles(f.confDir()).Load(profile)
+	case f.configFlag != "" || f.alphabet == "" || f.height == 0 || f.width == 0 || f.separator == "":
+		cfg, err = config.LoadConfigFile(f.configPath)
+	}
+	if err != nil {
+		return model.Config{}, "", fmt.Errorf("can't load config: %w", err)
+	}
+
+	if f.algorithm != "" {
+		cfg.Algorithm = model.Algorithm(f.algorithm)
+	}
+
+	if f.arrange != "" {
+		cfg.Arrangement = model.Arrangement(f.arrange)
+	}
+
+	if f.key != "" {
+		cfg.Key = f.key
+	}
+
+	if f.secondKey != "" {
+		cfg.SecondKey = f.secondKey
+	}
+
+	for i, key := range f.roundKeys {
+		if i == len(cfg.Rounds) {
+			cfg.Rounds = append(cfg.Rounds, model.Round{})
+		}
+		cfg.Rounds[i].Key = key
+	}
+
+	for i, size := range f.roundSizes {
+		if i == len(cfg.Rounds) {
+			return model.Config{}, "", errors.New("[round-size] given for more rounds than configured")
+		}
+		cfg.Rounds[i].Height, cfg.Rounds[i].Width = size[0], size[1]
+	}
+
+	if f.rowShift != 0 {
+		cfg.Rules.RowShift = f.rowShift
+	}
+
+	if f.colShift != 0 {
+		cfg.Rules.ColumnShift = f.colShift
+	}
+
+	if f.rectangle != "" {
+		cfg.Rules.Rectangle = model.Rectangle(f.rectangle)
+	}
+
+	if f.fill != "" {
+		cfg.Fill = model.Fill(f.fill)
+	}
+
+	if err := f.kdf.apply(&cfg); err != nil {
+		return model.Config{}, "", err
+	}
+
+	if f.alphabet != "" {
+		cfg.Chars = []rune(f.alphabet)
+	}
+
+	if f.height != 0 {
+		cfg.Height = f.height
+	}
+
+	if f.width != 0 {
+		cfg.Width = f.width
+	}
+
+	if f.depth != 0 {
+		cfg.Depth = f.depth
+	}
+
+	for _, opt := range []struct {
+		name  string
+		value string
+		dst   **rune
+	}{
+		{"separator", f.separator, &cfg.Separator},
+		{"pad", f.pad, &cfg.PadFiller},
+		{"alt", f.alt, &cfg.AltFiller},
+	} {
+		if opt.value == "" {
+			continue
+		}
+
+		char := []rune(opt.value)
+		if len(char) != 1 {
+			return model.Config{}, "", fmt.Errorf("[%s] must be a single character", opt.name)
+		}
+		*opt.dst = &char[0]
+	}
+
+	if f.passThru {
+		cfg.PassThrough = true
+	}
+
+	if f.strict {
+		cfg.StrictFillers = true
+	}
+
+	if f.foldCase {
+		cfg.FoldCase = true
+	}
+
+	if f.stripDiacr {
+		cfg.StripDiacritics = true
+	}
+
+	if f.dropUnkn {
+		cfg.DropUnknown = true
+	}
+
+	return cfg, profile, nil
+}
+
+func (f *engineFlags) confDir() string {
+	return filepath.Dir(f.configPath)
+}
+
+func (f *engineFlags) resolveProfile() (string, error) {
+	if f.profile != "" || f.configFlag != "" || f.preset != "" {
+		return f.profile, nil
+	}
+
+	return config.NewProfiles(f.confDir()).Default()
+}
+
+func fillNames() string {
+	names := make([]string, len(model.Fills))
+	for i, fill := range model.Fills {
+		names[i] = string(fill)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func keyName(profile string) string {
+	if profile == "" {
+		return keystore.DefaultName
+	}
+
+	return profile
+}
+
+func (f *engineFlags) engine(env Env) (*playfair.Engine, error) {
+	cfg, err := f.config(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return playfair.New(cfg)
+}
+
+func runCrypt(env Env, name string, args []string, proc func(*playfair.Engine, string) (string, error)) error {
+	var f cryptFlags
+
+	fs := newFlagSet(env, name)
+	fs.StringVar(&f.in, "in", "", "input file (default stdin)")
+	fs.StringVar(&f.out, "out", "", "output file (default stdout)")
+	if name == "encrypt" {
+		fs.BoolVar(&f.report, "report", false, "print normalization changes to stderr")
+	}
+	f.bind(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	engine, err := f.engine(env)
+	if err != nil {
+		return err
+	}
+
+	text, err := readInput(env, f.in)
+	if err != nil {
+		return err
+	}
+
+	if f.report {
+		if _, report := engine.Normalize(text); len(report.Changes) > 0 {
+			fmt.Fprintf(env.Stderr, "normalized: %s\n", report)
+		}
+	}
+
+	res, err := proc(engine, text)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(env, f.out, res)
+}
+
+func readInput(env Env, path string) (string, error) {
+	var (
+		text string
+		err  error
+	)
+
+	if path != "" {
+		text, err = file.Load(path)
+	} else {
+		var data []byte
+		data, err = io.ReadAll(env.Stdin)
+		text = string(data)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(text, "\n"), "\r"), nil
+}
+
+func writeOutput(env Env, path, text string) error {
+	if path != "" {
+		return file.Save(path, text)
+	}
+
+	_, err := fmt.Fprintln(env.Stdout, text)
+	return err
+}
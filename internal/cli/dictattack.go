@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/akaspb/playfair-cipher/internal/crack"
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+const progressWidth = 30
+
+func init() {
+	register("dict-attack", "try the words of a wordlist as keys and rank them by language score", runDictAttack)
+}
+
+func runDictAttack(env Env, args []string) error {
+	var (
+		f        engineFlags
+		lang     ngramFlags
+		in       string
+		wordlist string
+		sample   int
+		reverse  bool
+		digits   int
+		quiet    bool
+		opts     crack.DictOptions
+	)
+
+	fs := newFlagSet(env, "dict-attack")
+	fs.StringVar(&in, "in", "", "ciphertext file (default stdin)")
+	fs.StringVar(&wordlist, "wordlist", "", "file with one candidate key per line")
+	fs.IntVar(&sample, "sample", 300, "ciphertext chars deciphered per key, 0 for all")
+	fs.BoolVar(&reverse, "reverse", true, "also try every word reversed")
+	fs.IntVar(&digits, "digits", -1, "also try words with number suffixes of up to this many digits (default 1 if keys may hold digits)")
+	fs.IntVar(&opts.Top, "top", 10, "candidates to list")
+	fs.IntVar(&opts.Workers, "workers", 0, "keys tried in parallel (default one per CPU)")
+	fs.BoolVar(&quiet, "quiet", false, "don't print progress")
+	lang.bind(fs)
+	f.bind(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if wordlist == "" {
+		return errors.New("[wordlist] must be non-empty string")
+	}
+
+	cfg, _, err := f.baseConfig()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Algorithm.TwoKeys() && cfg.SecondKey == "" {
+		return errors.New("dict-attack searches the first key only, [key2] must be non-empty string")
+	}
+
+	for _, r := range cfg.Rounds {
+		if r.Key == "" {
+			return errors.New("dict-attack searches the first key only, [round-key] must be non-empty string")
+		}
+	}
+
+	if digits < 0 {
+		digits = 0
+		if cfg.KDF != nil || slices.Contains(cfg.Chars, '0') {
+			digits = 1
+		}
+	}
+
+	if opts.Model, err = lang.model(cfg); err != nil {
+		return err
+	}
+
+	alphabet, err := alphabetEngine(cfg)
+	if err != nil {
+		return err
+	}
+
+	text, err := readInput(env, in)
+	if err != nil {
+		return err
+	}
+
+	cipherText := alphabet.GridText(text)
+	if sample > 0 && sample < len(cipherText) {
+		cipherText = cipherText[:sample]
+	}
+	group := 2
+	if cfg.Algorithm == model.Cube {
+		group = 3
+	}
+	cipherText = cipherText[:len(cipherText)-len(cipherText)%group]
+
+	file, err := os.Open(wordlist)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	cr := &countReader{r: file}
+	sc := bufio.NewScanner(cr)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	keys := func(yield func(string) bool) {
+		for sc.Scan() {
+			word := strings.TrimSpace(sc.Text())
+			if word == "" {
+				continue
+			}
+
+			for key := range crack.Mutations(word, reverse, digits) {
+				if !yield(key) {
+					return
+				}
+			}
+		}
+	}
+
+	var final crack.DictProgress
+	if !quiet {
+		var last time.Time
+		opts.Progress = func(p crack.DictProgress) {
+			final = p
+			if time.Since(last) < 100*time.Millisecond {
+				return
+			}
+			last = time.Now()
+
+			printProgress(env, p, cr.n.Load(), info.Size())
+		}
+	}
+
+	cfg.Key = ""
+	opts.Config = cfg
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	top, err := crack.Dictionary(ctx, string(cipherText), keys, opts)
+	if !quiet {
+		printProgress(env, final, cr.n.Load(), info.Size())
+		fmt.Fprintln(env.Stderr)
+		if final.Err != nil && err == nil {
+			fmt.Fprintf(env.Stderr, "%d keys failed, first: %v\n", final.Failed, final.Err)
+		}
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		fmt.Fprintln(env.Stderr, "stopped early, best keys so far:")
+	case err != nil:
+		return err
+	}
+
+	if err := sc.Err(); err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "rank\tscore\tkey\tplaintext")
+	for i, c := range top {
+		fmt.Fprintf(tw, "%d\t%.3f\t%s\t%s\n", i+1, c.Score, c.Key, preview(c.Plaintext, 40))
+	}
+
+	return tw.Flush()
+}
+
+// countReader counts the bytes read for the progress bar.
+type countReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (cr *countReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n.Add(int64(n))
+
+	return n, err
+}
+
+func printProgress(env Env, p crack.DictProgress, done, total int64) {
+	fmt.Fprintf(env.Stderr, "\r%s  %d keys, %d without a matrix, %d failed  best %.3f %s ",
+		progressBar(done, total), p.Tried, p.Skipped, p.Failed, p.Best.Score, p.Best.Key)
+}
+
+func progressBar(done, total int64) string {
+	ratio := 1.0
+	if total > 0 {
+		ratio = min(float64(done)/float64(total), 1)
+	}
+
+	filled := int(ratio * progressWidth)
+
+	return fmt.Sprintf("[%s%s] %3.0f%%", strings.Repeat("#", filled), strings.Repeat("-", progressWidth-filled), 100*ratio)
+}
+
+func preview(text string, n int) string {
+	chars := []rune(text)
+	if len(chars) <= n {
+		return text
+	}
+
+	return string(chars[:n]) + "..."
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/config"
+	"github.com/akaspb/playfair-cipher/internal/keymatrix"
+	"github.com/akaspb/playfair-cipher/internal/keystore"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+func init() {
+	register("genkey", "generate random keys, optionally saving them to a profile", runGenkey)
+}
+
+func runGenkey(env Env, args []string) error {
+	var (
+		f    engineFlags
+		seed string
+		show bool
+		save bool
+	)
+
+	fs := newFlagSet(env, "genkey")
+	fs.StringVar(&seed, "seed", "", "seed phrase that makes the keys reproducible (default crypto/rand)")
+	fs.BoolVar(&show, "show", false, "print the matrices after the keys")
+	fs.BoolVar(&save, "save", false, "save the settings to the profile or config file and the keys to the keystore")
+	f.bind(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, profile, err := f.baseConfig()
+	if err != nil {
+		return err
+	}
+
+	gen := keymatrix.NewGenerator()
+	if seed != "" {
+		gen = keymatrix.NewSeededGenerator(seed)
+	}
+
+	keys := make([]*string, 0, 2+len(cfg.Rounds))
+	keys = append(keys, &cfg.Key)
+	if cfg.Algorithm.TwoKeys() {
+		keys = append(keys, &cfg.SecondKey)
+	}
+	for i := range cfg.Rounds {
+		keys = append(keys, &cfg.Rounds[i].Key)
+	}
+
+	for _, key := range keys {
+		perm, err := gen.Permutation(cfg.Chars)
+		if err != nil {
+			return err
+		}
+		*key = string(perm)
+	}
+
+	if save {
+		if err := newSalt(&cfg); err != nil {
+			return err
+		}
+	}
+
+	engine, err := playfair.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		fmt.Fprintln(env.Stdout, *key)
+	}
+
+	if show {
+		fmt.Fprintf(env.Stdout, "\n%s\n", engine)
+	}
+
+	if !save {
+		return nil
+	}
+
+	// the settings hold the salt of the keys, so they are written only once
+	// the keystore is unlocked
+	store, err := f.keystore.open(env, f.configPath)
+	if err != nil {
+		return err
+	}
+
+	if profile != "" {
+		err = config.NewProfiles(f.confDir()).Save(profile, cfg)
+	} else {
+		err = config.CreateConfigFile(f.configPath, cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	name := keyName(profile)
+	for _, entry := range profileKeys(store, name, "")[2:] {
+		store.Delete(entry[0])
+	}
+
+	store.Set(name, cfg.Key)
+	if cfg.Algorithm.TwoKeys() {
+		store.Set(keystore.SecondName(name), cfg.SecondKey)
+	}
+	for i, r := range cfg.Rounds {
+		store.Set(keystore.RoundName(name, i+2), r.Key)
+	}
+
+	return store.Save()
+}
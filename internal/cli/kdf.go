@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/internal/keymatrix"
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+type kdfFlags struct {
+	algorithm   string
+	salt        string
+	iterations  int
+	memory      uint
+	cost        int
+	blockSize   int
+	parallelism int
+}
+
+func (f *kdfFlags) bind(fs *flag.FlagSet) {
+	fs.StringVar(&f.algorithm, "kdf", "", "derive matrices from passphrase keys: "+kdfNames()+", or off")
+	fs.StringVar(&f.salt, "kdf-salt", "", "base64 kdf salt (default kept from config, random for new profiles)")
+	fs.IntVar(&f.iterations, "kdf-iterations", 0, "pbkdf2 iterations or argon2id passes")
+	fs.UintVar(&f.memory, "kdf-memory", 0, "argon2id memory in KiB")
+	fs.IntVar(&f.cost, "kdf-cost", 0, "scrypt cost N")
+	fs.IntVar(&f.blockSize, "kdf-block-size", 0, "scrypt block size r")
+	fs.IntVar(&f.parallelism, "kdf-parallelism", 0, "scrypt parallelism p or argon2id threads")
+}
+
+func (f *kdfFlags) apply(cfg *model.Config) error {
+	switch {
+	case f.algorithm == "off":
+		cfg.KDF = nil
+		return nil
+	case f.algorithm != "":
+		alg := model.KDFAlgorithm(f.algorithm)
+		if !alg.Valid() {
+			return fmt.Errorf("unknown kdf '%s'", f.algorithm)
+		}
+
+		if cfg.KDF == nil || cfg.KDF.Algorithm != alg {
+			var salt []byte
+			if cfg.KDF != nil {
+				salt = cfg.KDF.Salt
+			}
+			cfg.KDF = &model.KDF{Algorithm: alg, Salt: salt}
+		}
+	case cfg.KDF == nil:
+		return nil
+	}
+
+	kdf := *cfg.KDF
+	if f.salt != "" {
+		salt, err := base64.StdEncoding.DecodeString(f.salt)
+		if err != nil {
+			return fmt.Errorf("[kdf-salt] must be base64: %w", err)
+		}
+		kdf.Salt = salt
+	}
+
+	for _, opt := range []struct {
+		value int
+		dst   *int
+	}{
+		{f.iterations, &kdf.Iterations},
+		{f.cost, &kdf.Cost},
+		{f.blockSize, &kdf.BlockSize},
+		{f.parallelism, &kdf.Parallelism},
+	} {
+		if opt.value != 0 {
+			*opt.dst = opt.value
+		}
+	}
+
+	if f.memory != 0 {
+		kdf.Memory = uint32(f.memory)
+	}
+
+	cfg.KDF = &kdf
+	return nil
+}
+
+// newSalt gives a key derivation without a salt a random one before its
+// config is saved.
+func newSalt(cfg *model.Config) error {
+	if cfg.KDF == nil || len(cfg.KDF.Salt) > 0 {
+		return nil
+	}
+
+	salt, err := keymatrix.NewSalt()
+	if err != nil {
+		return err
+	}
+
+	cfg.KDF.Salt = salt
+	return nil
+}
+
+func kdfNames() string {
+	names := make([]string, len(model.KDFAlgorithms))
+	for i, alg := range model.KDFAlgorithms {
+		names[i] = string(alg)
+	}
+
+	return strings.Join(names, ", ")
+}
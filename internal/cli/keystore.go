@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/internal/config"
+	"github.com/akaspb/playfair-cipher/internal/keystore"
+	"golang.org/x/term"
+)
+
+func init() {
+	register("keystore", "store a key in the encrypted keystore", runKeystore)
+}
+
+type keystoreFlags struct {
+	path         string
+	passphraseFd int
+}
+
+func (f *keystoreFlags) bind(fs *flag.FlagSet) {
+	fs.StringVar(&f.path, "keystore", "", "path to keystore file (default next to config)")
+	fs.IntVar(&f.passphraseFd, "passphrase-fd", -1, "read keystore passphrase from file descriptor")
+}
+
+func (f *keystoreFlags) keystorePath(configPath string) string {
+	if f.path != "" {
+		return f.path
+	}
+
+	return config.KeystorePath(configPath)
+}
+
+func (f *keystoreFlags) open(env Env, configPath string) (*keystore.Store, error) {
+	passphrase, err := f.readPassphrase(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return keystore.Open(f.keystorePath(configPath), passphrase)
+}
+
+func (f *keystoreFlags) readPassphrase(env Env) ([]byte, error) {
+	if f.passphraseFd >= 0 {
+		fd := os.NewFile(uintptr(f.passphraseFd), "passphrase")
+		if fd == nil {
+			return nil, fmt.Errorf("invalid passphrase file descriptor %d", f.passphraseFd)
+		}
+		defer fd.Close()
+
+		line, err := bufio.NewReader(fd).ReadString('\n')
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("can't read passphrase: %w", err)
+		}
+
+		return []byte(strings.TrimRight(line, "\r\n")), nil
+	}
+
+	stdin, ok := env.Stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(stdin.Fd())) {
+		return nil, errors.New("keystore passphrase required: use --passphrase-fd when stdin is not a terminal")
+	}
+
+	fmt.Fprint(env.Stderr, "Keystore passphrase: ")
+	passphrase, err := term.ReadPassword(int(stdin.Fd()))
+	fmt.Fprintln(env.Stderr)
+
+	return passphrase, err
+}
+
+func runKeystore(env Env, args []string) error {
+	var (
+		f          keystoreFlags
+		configPath string
+		name       string
+		key        string
+		secondKey  string
+		roundKeys  []string
+		remove     bool
+	)
+
+	fs := newFlagSet(env, "keystore")
+	fs.StringVar(&configPath, "config", "", "path to config file (default $"+config.PathEnv+" or user config dir)")
+	fs.StringVar(&name, "name", keystore.DefaultName, "keystore entry name")
+	fs.StringVar(&key, "key", "", "key to store")
+	fs.StringVar(&secondKey, "key2", "", "second key to store (two-square, four-square)")
+	fs.Func("round-key", "key of the next extra round to store, repeatable", func(s string) error {
+		roundKeys = append(roundKeys, s)
+		return nil
+	})
+	fs.BoolVar(&remove, "delete", false, "delete the entry instead of storing it")
+	f.bind(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if key == "" && secondKey == "" && len(roundKeys) == 0 && !remove {
+		return errors.New("[key], [key2] or [round-key] must be non-empty string")
+	}
+
+	configPath, err := config.Path(configPath)
+	if err != nil {
+		return err
+	}
+
+	store, err := f.open(env, configPath)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case remove:
+		for _, name := range profileKeys(store, name, "") {
+			store.Delete(name[0])
+		}
+	default:
+		if key != "" {
+			store.Set(name, key)
+		}
+
+		if secondKey != "" {
+			store.Set(keystore.SecondName(name), secondKey)
+		}
+
+		if len(roundKeys) > 0 {
+			for _, name := range profileKeys(store, name, "")[2:] {
+				store.Delete(name[0])
+			}
+		}
+
+		for i, roundKey := range roundKeys {
+			store.Set(keystore.RoundName(name, i+2), roundKey)
+		}
+	}
+
+	return store.Save()
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"compress/gzip"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/internal/ngram"
+)
+
+func init() {
+	register("ngrams", "build n-gram tables over the alphabet from text files, gzipped or not", runNgrams)
+}
+
+func runNgrams(env Env, args []string) error {
+	var (
+		f     engineFlags
+		out   string
+		order int
+	)
+
+	fs := newFlagSet(env, "ngrams")
+	fs.StringVar(&out, "out", "", "table file to write")
+	fs.IntVar(&order, "order", 0, "longest n-grams counted, up to 4 (default the highest the alphabet allows)")
+	f.bind(fs)
+
+	files, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		return errors.New("[out] must be non-empty string")
+	}
+
+	if len(files) == 0 {
+		return errors.New("text files to count must be given")
+	}
+
+	cfg, _, err := f.baseConfig()
+	if err != nil {
+		return err
+	}
+
+	if order == 0 {
+		order = ngram.OrderFor(len(cfg.Chars))
+	}
+
+	readers := make([]io.Reader, 0, 2*len(files))
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		var r io.Reader = file
+		if strings.HasSuffix(name, ".gz") {
+			if r, err = gzip.NewReader(file); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+
+		// a line break keeps n-grams from running across files
+		readers = append(readers, r, strings.NewReader("\n"))
+	}
+
+	m, err := ngram.Train(io.MultiReader(readers...), cfg.Chars, cfg.Merges, order)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	size, err := m.WriteTo(file)
+	if err != nil {
+		return err
+	}
+
+	seen := make([]string, order)
+	for n := range seen {
+		seen[n] = fmt.Sprintf("%d %d-grams", m.Seen(n+1), n+1)
+	}
+	fmt.Fprintf(env.Stdout, "%s seen over %d chars, %d bytes written to %s\n", strings.Join(seen, ", "), len(cfg.Chars), size, out)
+
+	return file.Close()
+}
+
+type ngramFlags struct {
+	source string
+}
+
+func (f *ngramFlags) bind(fs *flag.FlagSet) {
+	fs.StringVar(&f.source, "ngrams", "", "n-gram table from 'playfair ngrams' or built-in "+strings.Join(ngram.Languages, ", ")+" (default by alphabet)")
+}
+
+// model loads the n-grams chosen by the flag over the alphabet of cfg.
+func (f *ngramFlags) model(cfg model.Config) (*ngram.Model, error) {
+	switch {
+	case f.source == "":
+		return ngram.Default(cfg.Chars, cfg.Merges)
+	case slices.Contains(ngram.Languages, f.source):
+		return ngram.Builtin(f.source, cfg.Chars, cfg.Merges)
+	}
+
+	file, err := os.Open(f.source)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	m, err := ngram.Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.source, err)
+	}
+
+	return m.Project(cfg.Chars, cfg.Merges)
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/config"
+)
+
+func init() {
+	register("presets", "list built-in alphabet presets", runPresets)
+}
+
+func runPresets(env Env, args []string) error {
+	fs := newFlagSet(env, "presets")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	for _, p := range config.Presets() {
+		merges := ""
+		if len(p.Merges) > 0 {
+			merges = "  merges " + config.FormatMerges(p.Merges)
+		}
+
+		fmt.Fprintf(env.Stdout, "%-8s %-5s  separator '%c'%s  %s\n", p.Name, p.Size(), p.Separator, merges, p.Description)
+	}
+
+	return nil
+}
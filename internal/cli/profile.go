@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/config"
+	"github.com/akaspb/playfair-cipher/internal/keystore"
+)
+
+func init() {
+	register("profile", "manage named profiles: list, create, rename, delete, default", runProfile)
+}
+
+func runProfile(env Env, args []string) error {
+	if len(args) == 0 {
+		return errors.New("profile action required: list, create, rename, delete or default")
+	}
+
+	action, args := args[0], args[1:]
+
+	var f engineFlags
+	fs := newFlagSet(env, "profile "+action)
+	f.bind(fs)
+
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if err := f.resolve(); err != nil {
+		return err
+	}
+
+	profiles := config.NewProfiles(f.confDir())
+
+	switch action {
+	case "list":
+		def, err := profiles.Default()
+		if err != nil {
+			return err
+		}
+
+		list, err := profiles.List()
+		if err != nil {
+			return err
+		}
+
+		for _, name := range list {
+			mark := " "
+			if name == def {
+				mark = "*"
+			}
+			fmt.Fprintf(env.Stdout, "%s %s\n", mark, name)
+		}
+
+		return nil
+	case "create":
+		if len(names) != 1 {
+			return errors.New("usage: playfair profile create NAME [flags]")
+		}
+
+		if profiles.Exists(names[0]) {
+			return fmt.Errorf("profile '%s' already exists", names[0])
+		}
+
+		cfg, _, err := f.baseConfig()
+		if err != nil {
+			return err
+		}
+
+		if err := newSalt(&cfg); err != nil {
+			return err
+		}
+
+		return profiles.Save(names[0], cfg)
+	case "rename":
+		if len(names) != 2 {
+			return errors.New("usage: playfair profile rename OLD NEW [flags]")
+		}
+
+		if err := profiles.Rename(names[0], names[1]); err != nil {
+			return err
+		}
+
+		return f.updateKeystore(env, func(store *keystore.Store) {
+			for _, name := range profileKeys(store, names[0], names[1]) {
+				if key, ok := store.Get(name[0]); ok {
+					store.Set(name[1], key)
+					store.Delete(name[0])
+				}
+			}
+		})
+	case "delete":
+		if len(names) != 1 {
+			return errors.New("usage: playfair profile delete NAME [flags]")
+		}
+
+		if err := profiles.Delete(names[0]); err != nil {
+			return err
+		}
+
+		return f.updateKeystore(env, func(store *keystore.Store) {
+			for _, name := range profileKeys(store, names[0], "") {
+				store.Delete(name[0])
+			}
+		})
+	case "default":
+		switch len(names) {
+		case 0:
+			def, err := profiles.Default()
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(env.Stdout, def)
+			return nil
+		case 1:
+			return profiles.SetDefault(names[0])
+		default:
+			return errors.New("usage: playfair profile default [NAME|\"\"] [flags]")
+		}
+	}
+
+	return fmt.Errorf("unknown profile action '%s'", action)
+}
+
+// profileKeys pairs the keystore entries of profile from with their names
+// under profile to.
+func profileKeys(store *keystore.Store, from, to string) [][2]string {
+	names := [][2]string{
+		{from, to},
+		{keystore.SecondName(from), keystore.SecondName(to)},
+	}
+
+	for n := 2; ; n++ {
+		if _, ok := store.Get(keystore.RoundName(from, n)); !ok {
+			break
+		}
+
+		names = append(names, [2]string{keystore.RoundName(from, n), keystore.RoundName(to, n)})
+	}
+
+	return names
+}
+
+func (f *engineFlags) updateKeystore(env Env, update func(store *keystore.Store)) error {
+	if !keystore.Exists(f.keystore.keystorePath(f.configPath)) {
+		return nil
+	}
+
+	store, err := f.keystore.open(env, f.configPath)
+	if err != nil {
+		return err
+	}
+
+	update(store)
+
+	return store.Save()
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/pkg/analysis"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+func init() {
+	register("stats", "print letter and digraph statistics of a text", runStats)
+}
+
+func runStats(env Env, args []string) error {
+	var (
+		f       engineFlags
+		lang    ngramFlags
+		in      string
+		asJSON  bool
+		top     int
+		encrypt bool
+		opts    analysis.Options
+	)
+
+	fs := newFlagSet(env, "stats")
+	fs.StringVar(&in, "in", "", "text file (default stdin)")
+	fs.BoolVar(&asJSON, "json", false, "print the full report as JSON")
+	fs.IntVar(&top, "top", 10, "letters, digraphs and reversed pairs to list, 0 for all")
+	fs.BoolVar(&encrypt, "encrypt", false, "encrypt the text first and analyze the ciphertext")
+	fs.BoolVar(&opts.Overlapping, "overlapping", false, "count every digraph instead of the aligned pairs")
+	lang.bind(fs)
+	f.bind(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, _, err := f.baseConfig()
+	if encrypt {
+		cfg, err = f.config(env)
+	}
+	if err != nil {
+		return err
+	}
+
+	var engine *playfair.Engine
+	switch {
+	case !encrypt:
+		engine, err = alphabetEngine(cfg)
+	case cfg.Key == "":
+		return errors.New("[key] must be non-empty string")
+	default:
+		engine, err = playfair.New(cfg)
+	}
+	if err != nil {
+		return err
+	}
+
+	text, err := readInput(env, in)
+	if err != nil {
+		return err
+	}
+
+	if encrypt {
+		if text, err = engine.Encrypt(text); err != nil {
+			return err
+		}
+	}
+
+	if lang.source == "" {
+		opts.Expected = analysis.Expected(cfg.Chars, cfg.Merges)
+	} else {
+		m, err := lang.model(cfg)
+		if err != nil {
+			return err
+		}
+		opts.Expected = m.Frequencies()
+	}
+
+	// grid text may hold spaces and punctuation the default filter drops
+	opts.Alphabet = cfg.Chars
+	report := analysis.Analyze(engine.GridText(text), opts)
+	if asJSON {
+		enc := json.NewEncoder(env.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	return printReport(env.Stdout, report, top)
+}
+
+// alphabetEngine builds an engine over the alphabet of cfg for GridText,
+// whatever keys cfg has.
+func alphabetEngine(cfg model.Config) (*playfair.Engine, error) {
+	cfg.Key, cfg.SecondKey = string(cfg.Chars), string(cfg.Chars)
+	cfg.KDF, cfg.Rounds = nil, nil
+
+	return playfair.New(cfg)
+}
+
+func printReport(w io.Writer, r analysis.Report, top int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "length:\t%d\n", r.Length)
+	fmt.Fprintf(tw, "index of coincidence:\t%.4f\n", r.IndexOfCoincidence)
+	if r.LanguageIC > 0 {
+		fmt.Fprintf(tw, "language IC:\t%.4f\n", r.LanguageIC)
+		fmt.Fprintf(tw, "chi-square:\t%.1f\n", r.ChiSquare)
+	}
+	fmt.Fprintf(tw, "doubled pairs:\t%d\n", r.DoubledDigraphs)
+	fmt.Fprintf(tw, "reversed pairs:\t%d\n", len(r.ReversedPairs))
+
+	fmt.Fprintln(tw, "\nletter\tcount\t%")
+	for _, c := range limit(r.Letters, top) {
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\n", showChars(c.Text), c.Count, percent(c.Count, r.Length))
+	}
+
+	digraphs := 0
+	for _, c := range r.Digraphs {
+		digraphs += c.Count
+	}
+
+	fmt.Fprintln(tw, "\ndigraph\tcount\t%")
+	for _, c := range limit(r.Digraphs, top) {
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\n", showChars(c.Text), c.Count, percent(c.Count, digraphs))
+	}
+
+	if len(r.ReversedPairs) > 0 {
+		fmt.Fprintln(tw, "\nreversed\tcounts")
+		for _, p := range limit(r.ReversedPairs, top) {
+			fmt.Fprintf(tw, "%s/%s\t%d/%d\n", showChars(p.Pair), showChars(p.Reverse), p.Count, p.ReverseCount)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func limit[T any](items []T, n int) []T {
+	if n > 0 && n < len(items) {
+		return items[:n]
+	}
+
+	return items
+}
+
+func percent(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return 100 * float64(count) / float64(total)
+}
+
+// showChars makes spaces visible in table cells.
+func showChars(text string) string {
+	return strings.ReplaceAll(text, " ", "␣")
+}
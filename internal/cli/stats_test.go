@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akaspb/playfair-cipher/pkg/analysis"
+)
+
+func TestStatsKeepsPunctuationAlphabet(t *testing.T) {
+	confFile := filepath.Join(t.TempDir(), "config.json")
+	conf := `{"version": 1, "height": 4, "width": 9, "alphabet": "abcdefghijklmnopqrstuvwxyz .,!:-()?#", "separator": "#"}`
+	if err := os.WriteFile(confFile, []byte(conf), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	env := Env{Stdin: strings.NewReader("a b,a b,"), Stdout: &stdout, Stderr: &stderr}
+	if err := Run(env, []string{"stats", "--config", confFile, "--json"}); err != nil {
+		t.Fatalf("%v: %s", err, stderr.String())
+	}
+
+	var report analysis.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Length != 8 {
+		t.Errorf("length = %d, want 8", report.Length)
+	}
+
+	want := []analysis.Count{{Text: "a ", Count: 2}, {Text: "b,", Count: 2}}
+	if len(report.Digraphs) != len(want) || report.Digraphs[0] != want[0] || report.Digraphs[1] != want[1] {
+		t.Errorf("digraphs = %v, want %v", report.Digraphs, want)
+	}
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/validate"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+func init() {
+	register("validate", "report every problem of a ciphertext and whether it came from the key", runValidate)
+}
+
+func runValidate(env Env, args []string) error {
+	var (
+		f      engineFlags
+		lang   ngramFlags
+		in     string
+		asJSON bool
+	)
+
+	fs := newFlagSet(env, "validate")
+	fs.StringVar(&in, "in", "", "ciphertext file (default stdin)")
+	fs.BoolVar(&asJSON, "json", false, "print the report as JSON")
+	lang.bind(fs)
+	f.bind(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, err := f.config(env)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Key == "" {
+		return errors.New("[key] must be non-empty string")
+	}
+
+	engine, err := playfair.New(cfg)
+	if err != nil {
+		return err
+	}
+
+	text, err := readInput(env, in)
+	if err != nil {
+		return err
+	}
+
+	// alphabets without n-grams are judged by structure only
+	m, err := lang.model(cfg)
+	if err != nil && lang.source != "" {
+		return err
+	}
+
+	report := validate.Check(engine, text, m)
+	if asJSON {
+		enc := json.NewEncoder(env.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	for _, p := range report.Problems {
+		fmt.Fprintf(env.Stdout, "offset %d: %s: %s\n", p.Offset, p.Kind, p.Message)
+	}
+
+	if len(report.Ambiguous) > 0 {
+		fmt.Fprintf(env.Stdout, "separators that may be fillers or text at plaintext offsets %v\n", report.Ambiguous)
+	}
+
+	fmt.Fprintf(env.Stdout, "verdict: %s, %s", report.Verdict, report.Reason)
+	if report.Fit != nil {
+		fmt.Fprintf(env.Stdout, " (fit %.2f)", *report.Fit)
+	}
+	fmt.Fprintln(env.Stdout)
+
+	return nil
+}
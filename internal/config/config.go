@@ -1,138 +1,458 @@
 package config
 
 import (
+	_ "embed"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
-	"unicode"
 
 	"github.com/akaspb/playfair-cipher/internal/model"
 )
 
-func CreateConfigFile(c model.Config) error {
-	execPath, err := os.Executable()
-	if err != nil {
-		log.Fatal(err)
+const (
+	PathEnv = "PLAYFAIR_CONFIG"
+	Version = 1
+)
+
+//go:embed default.json
+var defaultConfig []byte
+
+type fileConfig struct {
+	Version         int               `json:"version"`
+	Algorithm       string            `json:"algorithm,omitempty"`
+	Arrangement     string            `json:"arrangement,omitempty"`
+	Rounds          []fileRound       `json:"rounds,omitempty"`
+	RowShift        int               `json:"row_shift,omitempty"`
+	ColumnShift     int               `json:"column_shift,omitempty"`
+	Rectangle       string            `json:"rectangle,omitempty"`
+	Fill            string            `json:"fill,omitempty"`
+	KDF             *fileKDF          `json:"kdf,omitempty"`
+	Height          int               `json:"height"`
+	Width           int               `json:"width"`
+	Depth           int               `json:"depth,omitempty"`
+	Alphabet        string            `json:"alphabet"`
+	Separator       string            `json:"separator"`
+	PadFiller       string            `json:"pad_filler,omitempty"`
+	AltFiller       string            `json:"alt_filler,omitempty"`
+	Merges          map[string]string `json:"merges,omitempty"`
+	FoldCase        bool              `json:"fold_case,omitempty"`
+	StripDiacritics bool              `json:"strip_diacritics,omitempty"`
+	DropUnknown     bool              `json:"drop_unknown,omitempty"`
+	PassThrough     bool              `json:"pass_through,omitempty"`
+	StrictFillers   bool              `json:"strict_fillers,omitempty"`
+}
+
+// fileRound keeps the dimensions of an extra round, its key lives in the keystore.
+type fileRound struct {
+	Height int `json:"height,omitempty"`
+	Width  int `json:"width,omitempty"`
+}
+
+// fileKDF keeps the key derivation settings, the salt is base64 encoded.
+type fileKDF struct {
+	Algorithm   string `json:"algorithm"`
+	Salt        []byte `json:"salt"`
+	Iterations  int    `json:"iterations,omitempty"`
+	Memory      uint32 `json:"memory,omitempty"`
+	Cost        int    `json:"cost,omitempty"`
+	BlockSize   int    `json:"block_size,omitempty"`
+	Parallelism int    `json:"parallelism,omitempty"`
+}
+
+func Path(explicit string) (string, error) {
+	if explicit != "" {
+		// a legacy file is migrated to JSON next to it
+		if base, ok := strings.CutSuffix(explicit, ".txt"); ok {
+			return base + ".json", nil
+		}
+
+		return explicit, nil
+	}
+
+	if env := os.Getenv(PathEnv); env != "" {
+		return env, nil
 	}
 
-	confFile := filepath.Join(execPath, "..", "..", "config", "config.txt")
+	return defaultPath()
+}
 
-	file, err := os.Create(confFile)
+func defaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
 	if err != nil {
-		return err
+		return "", fmt.Errorf("can't resolve config location, use --config or %s: %w", PathEnv, err)
 	}
-	defer file.Close()
 
-	cfgText, err := createConfigText(c)
+	return filepath.Join(dir, "playfair", "config.json"), nil
+}
+
+// isDefaultPath reports whether confFile is the one in the user config dir,
+// the only file allowed to be missing.
+func isDefaultPath(confFile string) bool {
+	def, err := defaultPath()
+	return err == nil && filepath.Clean(confFile) == def
+}
+
+func KeystorePath(confFile string) string {
+	return filepath.Join(filepath.Dir(confFile), "keystore.json")
+}
+
+func Default() (model.Config, error) {
+	return loadConfigJSON(defaultConfig)
+}
+
+func CreateConfigFile(confFile string, c model.Config) error {
+	data, err := createConfigJSON(c)
 	if err != nil {
 		return err
 	}
 
-	_, err = file.WriteString(cfgText)
+	if err := os.MkdirAll(filepath.Dir(confFile), 0755); err != nil {
+		return err
+	}
 
-	return err
+	return os.WriteFile(confFile, data, 0644)
 }
 
-func createConfigText(c model.Config) (string, error) {
-	sb := strings.Builder{}
-
-	sb.WriteString(fmt.Sprintf("%d %d\n", c.Height, c.Width))
+func LoadConfigFile(confFile string) (model.Config, error) {
+	confData, err := os.ReadFile(confFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return migrateLegacyFile(confFile)
+	}
+	if err != nil {
+		return model.Config{}, err
+	}
 
-	for i := 0; i < c.Height; i++ {
-		for j := 0; j < c.Width; j++ {
-			sb.WriteRune(c.Chars[i*c.Width+j])
-		}
-		sb.WriteRune('\n')
+	if !isLegacyText(confData) {
+		return loadConfigJSON(confData)
 	}
 
-	idx := findEl(c.Chars, *c.Separator)
-	if idx == -1 {
-		return "", fmt.Errorf("can't find separator in chars")
+	c, err := loadLegacyText(string(confData))
+	if err != nil {
+		return model.Config{}, err
 	}
 
-	sb.WriteString(fmt.Sprintf("%d\n", idx))
+	// the file stays readable in the legacy format if it can't be backed up
+	if err := os.Rename(confFile, confFile+".bak"); err == nil {
+		_ = CreateConfigFile(confFile, c)
+	}
 
-	return sb.String(), nil
+	return c, nil
 }
 
-func findEl[T comparable](slc []T, el T) int {
-	for i, slcEl := range slc {
-		if slcEl == el {
-			return i
+// migrateLegacyFile converts the legacy file next to confFile, or else the
+// one the first releases kept next to the executable, to JSON at confFile.
+// The first is renamed to .bak, the second is left in place. Only the
+// default confFile falls back to the second and then to Default.
+func migrateLegacyFile(confFile string) (model.Config, error) {
+	legacyFile, backup := legacyPath(confFile), true
+	if legacyFile == "" || !fileExists(legacyFile) {
+		if !isDefaultPath(confFile) {
+			return model.Config{}, fmt.Errorf("config file %s doesn't exist", confFile)
 		}
+
+		legacyFile, backup = executableLegacyPath(), false
 	}
 
-	return -1
-}
+	if legacyFile == "" || !fileExists(legacyFile) {
+		return Default()
+	}
 
-func LoadConfigFile() (model.Config, error) {
-	execPath, err := os.Executable()
+	legacyData, err := os.ReadFile(legacyFile)
 	if err != nil {
-		log.Fatal(err)
+		return model.Config{}, err
 	}
 
-	confFile := filepath.Join(execPath, "..", "..", "config", "config.txt")
-
-	confData, err := os.ReadFile(confFile)
+	var c model.Config
+	if isLegacyText(legacyData) {
+		c, err = loadLegacyText(string(legacyData))
+	} else {
+		c, err = loadConfigJSON(legacyData)
+	}
 	if err != nil {
-		return model.Config{}, err
+		return model.Config{}, fmt.Errorf("can't migrate %s: %w", legacyFile, err)
+	}
+
+	if err := CreateConfigFile(confFile, c); err == nil && backup {
+		_ = os.Rename(legacyFile, legacyFile+".bak")
 	}
 
-	return loadConfigText(string(confData))
+	return c, nil
 }
 
-func loadConfigText(cfgText string) (model.Config, error) {
-	c := model.Config{}
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
 
-	lines := strings.Split(cfgText, "\n")
-	for i, line := range lines {
-		lines[i] = strings.TrimFunc(line, func(r rune) bool {
-			return !unicode.IsGraphic(r)
-		})
+func createConfigJSON(c model.Config) ([]byte, error) {
+	if c.Separator == nil {
+		return nil, errors.New("can't save config without separator")
 	}
 
-	if len(lines) <= 2 {
-		return model.Config{}, fmt.Errorf("incorrect config file")
+	fc := fileConfig{
+		Version:         Version,
+		Algorithm:       string(c.Algorithm),
+		Arrangement:     string(c.Arrangement),
+		Rounds:          roundsToJSON(c.Rounds),
+		RowShift:        c.Rules.RowShift,
+		ColumnShift:     c.Rules.ColumnShift,
+		Rectangle:       string(c.Rules.Rectangle),
+		Fill:            string(c.Fill),
+		KDF:             kdfToJSON(c.KDF),
+		Height:          c.Height,
+		Width:           c.Width,
+		Depth:           c.Depth,
+		Alphabet:        string(c.Chars),
+		Separator:       string(*c.Separator),
+		PadFiller:       runeToString(c.PadFiller),
+		AltFiller:       runeToString(c.AltFiller),
+		Merges:          mergesToJSON(c.Merges),
+		FoldCase:        c.FoldCase,
+		StripDiacritics: c.StripDiacritics,
+		DropUnknown:     c.DropUnknown,
+		PassThrough:     c.PassThrough,
+		StrictFillers:   c.StrictFillers,
 	}
 
-	_, err := fmt.Sscanf(lines[0], "%d %d", &c.Height, &c.Width)
+	data, err := json.MarshalIndent(fc, "", "  ")
 	if err != nil {
-		return model.Config{}, fmt.Errorf("can't read matrix height and width from config file: %w", err)
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
+
+func loadConfigJSON(data []byte) (model.Config, error) {
+	var probe struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return model.Config{}, fmt.Errorf("can't read config file: %w", err)
+	}
+
+	if probe.Version != Version {
+		return model.Config{}, fmt.Errorf("unsupported config version %d, this build reads version %d", probe.Version, Version)
+	}
+
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return model.Config{}, fmt.Errorf("can't read config file: %w", err)
 	}
 
-	if c.Height < 1 {
+	if fc.Height < 1 {
 		return model.Config{}, fmt.Errorf("incorrect height value")
 	}
 
-	if c.Width < 1 {
-		return model.Config{}, fmt.Errorf("incorrect width valuee")
+	if fc.Width < 1 {
+		return model.Config{}, fmt.Errorf("incorrect width value")
 	}
 
-	if len(lines) < c.Height+2 {
-		return model.Config{}, fmt.Errorf("incorrext config file")
+	c := model.Config{
+		Algorithm:   model.Algorithm(fc.Algorithm),
+		Arrangement: model.Arrangement(fc.Arrangement),
+		Rounds:      roundsFromJSON(fc.Rounds),
+		Fill:        model.Fill(fc.Fill),
+		KDF:         kdfFromJSON(fc.KDF),
+		Rules: model.Rules{
+			RowShift:    fc.RowShift,
+			ColumnShift: fc.ColumnShift,
+			Rectangle:   model.Rectangle(fc.Rectangle),
+		},
+		Height:          fc.Height,
+		Width:           fc.Width,
+		Depth:           fc.Depth,
+		Chars:           []rune(fc.Alphabet),
+		FoldCase:        fc.FoldCase,
+		StripDiacritics: fc.StripDiacritics,
+		DropUnknown:     fc.DropUnknown,
+		PassThrough:     fc.PassThrough,
+		StrictFillers:   fc.StrictFillers,
 	}
 
-	for _, line := range lines[1 : c.Height+1] {
-		c.Chars = append(c.Chars, []rune(line)...)
+	switch c.Algorithm {
+	case "", model.Playfair, model.TwoSquare, model.FourSquare, model.Cube:
+	default:
+		return model.Config{}, fmt.Errorf("unknown algorithm '%s'", fc.Algorithm)
 	}
 
-	if len(c.Chars) != c.Height*c.Width {
-		return model.Config{}, fmt.Errorf("incorrext matrix")
+	switch c.Arrangement {
+	case "", model.Horizontal, model.Vertical:
+	default:
+		return model.Config{}, fmt.Errorf("unknown arrangement '%s'", fc.Arrangement)
 	}
 
-	var idx int
-	_, err = fmt.Sscanf(lines[c.Height+1], "%d", &idx)
-	if err != nil {
-		return model.Config{}, fmt.Errorf("can't read separator position in matrix from config file: %w", err)
+	switch c.Rules.Rectangle {
+	case "", model.SameRow, model.SameColumn:
+	default:
+		return model.Config{}, fmt.Errorf("unknown rectangle rule '%s'", fc.Rectangle)
+	}
+
+	if !c.Fill.Valid() {
+		return model.Config{}, fmt.Errorf("unknown fill '%s'", fc.Fill)
+	}
+
+	if c.KDF != nil {
+		if !c.KDF.Algorithm.Valid() {
+			return model.Config{}, fmt.Errorf("unknown kdf '%s'", fc.KDF.Algorithm)
+		}
+
+		if len(c.KDF.Salt) == 0 {
+			return model.Config{}, errors.New("kdf salt must be set in config file")
+		}
 	}
 
-	if !(0 <= idx && idx < len(c.Chars)) {
-		return model.Config{}, fmt.Errorf("incorrect separator position in matrix from config file")
+	for i, r := range fc.Rounds {
+		if r.Height < 0 || r.Width < 0 || (r.Height == 0) != (r.Width == 0) {
+			return model.Config{}, fmt.Errorf("incorrect dimensions of round %d", i+2)
+		}
+	}
+
+	if c.Algorithm == model.Cube {
+		if c.Depth < 1 || len(c.Chars) != c.Height*c.Width*c.Depth {
+			return model.Config{}, fmt.Errorf("alphabet length %d doesn't match %dx%dx%d cube", len(c.Chars), c.Height, c.Width, c.Depth)
+		}
+	} else if len(c.Chars) != c.Height*c.Width {
+		return model.Config{}, fmt.Errorf("alphabet length %d doesn't match %dx%d matrix", len(c.Chars), c.Height, c.Width)
+	}
+
+	var err error
+	if c.Separator, err = stringToRune(fc.Separator, "separator"); err != nil {
+		return model.Config{}, err
+	}
+
+	if c.Separator == nil {
+		return model.Config{}, errors.New("separator must be set in config file")
 	}
 
-	c.Separator = &(c.Chars[idx])
+	if c.PadFiller, err = stringToRune(fc.PadFiller, "pad_filler"); err != nil {
+		return model.Config{}, err
+	}
+
+	if c.AltFiller, err = stringToRune(fc.AltFiller, "alt_filler"); err != nil {
+		return model.Config{}, err
+	}
+
+	if c.Merges, err = mergesFromJSON(fc.Merges); err != nil {
+		return model.Config{}, err
+	}
 
 	return c, nil
 }
+
+func roundsToJSON(rounds []model.Round) []fileRound {
+	if len(rounds) == 0 {
+		return nil
+	}
+
+	res := make([]fileRound, len(rounds))
+	for i, r := range rounds {
+		res[i] = fileRound{Height: r.Height, Width: r.Width}
+	}
+
+	return res
+}
+
+func kdfToJSON(kdf *model.KDF) *fileKDF {
+	if kdf == nil {
+		return nil
+	}
+
+	return &fileKDF{
+		Algorithm:   string(kdf.Algorithm),
+		Salt:        kdf.Salt,
+		Iterations:  kdf.Iterations,
+		Memory:      kdf.Memory,
+		Cost:        kdf.Cost,
+		BlockSize:   kdf.BlockSize,
+		Parallelism: kdf.Parallelism,
+	}
+}
+
+func kdfFromJSON(kdf *fileKDF) *model.KDF {
+	if kdf == nil {
+		return nil
+	}
+
+	return &model.KDF{
+		Algorithm:   model.KDFAlgorithm(kdf.Algorithm),
+		Salt:        kdf.Salt,
+		Iterations:  kdf.Iterations,
+		Memory:      kdf.Memory,
+		Cost:        kdf.Cost,
+		BlockSize:   kdf.BlockSize,
+		Parallelism: kdf.Parallelism,
+	}
+}
+
+func roundsFromJSON(rounds []fileRound) []model.Round {
+	if len(rounds) == 0 {
+		return nil
+	}
+
+	res := make([]model.Round, len(rounds))
+	for i, r := range rounds {
+		res[i] = model.Round{Height: r.Height, Width: r.Width}
+	}
+
+	return res
+}
+
+func mergesToJSON(merges map[rune]rune) map[string]string {
+	if len(merges) == 0 {
+		return nil
+	}
+
+	res := make(map[string]string, len(merges))
+	for from, to := range merges {
+		res[string(from)] = string(to)
+	}
+
+	return res
+}
+
+func mergesFromJSON(merges map[string]string) (map[rune]rune, error) {
+	if len(merges) == 0 {
+		return nil, nil
+	}
+
+	res := make(map[rune]rune, len(merges))
+	for from, to := range merges {
+		fromRune, err := stringToRune(from, "merges")
+		if err != nil || fromRune == nil {
+			return nil, fmt.Errorf("incorrect merge '%s' -> '%s'", from, to)
+		}
+
+		toRune, err := stringToRune(to, "merges")
+		if err != nil || toRune == nil {
+			return nil, fmt.Errorf("incorrect merge '%s' -> '%s'", from, to)
+		}
+
+		res[*fromRune] = *toRune
+	}
+
+	return res, nil
+}
+
+func runeToString(r *rune) string {
+	if r == nil {
+		return ""
+	}
+
+	return string(*r)
+}
+
+func stringToRune(s, field string) (*rune, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	chars := []rune(s)
+	if len(chars) != 1 {
+		return nil, fmt.Errorf("'%s' must be a single character", field)
+	}
+
+	return &chars[0], nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const legacyText = "2 3\nabc\ndef\n5\npassthrough\n"
+
+func TestLoadConfigFileMigratesLegacyFile(t *testing.T) {
+	dir := t.TempDir()
+	confFile := filepath.Join(dir, "config.json")
+	writeFile(t, filepath.Join(dir, "config.txt"), legacyText)
+
+	c, err := LoadConfigFile(confFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkLegacyConfig(t, c.Height, c.Width, string(c.Chars), *c.Separator, c.PassThrough)
+
+	if !fileExists(filepath.Join(dir, "config.txt.bak")) || fileExists(filepath.Join(dir, "config.txt")) {
+		t.Error("config.txt wasn't renamed to config.txt.bak")
+	}
+
+	if c, err = LoadConfigFile(confFile); err != nil {
+		t.Fatal(err)
+	}
+
+	checkLegacyConfig(t, c.Height, c.Width, string(c.Chars), *c.Separator, c.PassThrough)
+}
+
+func TestLoadConfigFileRewritesLegacyJSONFile(t *testing.T) {
+	confFile := filepath.Join(t.TempDir(), "config.json")
+	writeFile(t, confFile, legacyText)
+
+	c, err := LoadConfigFile(confFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkLegacyConfig(t, c.Height, c.Width, string(c.Chars), *c.Separator, c.PassThrough)
+
+	backup, err := os.ReadFile(confFile + ".bak")
+	if err != nil || string(backup) != legacyText {
+		t.Errorf("backup = %q, %v, want the legacy file", backup, err)
+	}
+
+	data, err := os.ReadFile(confFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if isLegacyText(data) {
+		t.Errorf("config file wasn't rewritten as JSON: %q", data)
+	}
+}
+
+func TestLoadConfigFileKeepsBrokenLegacyFile(t *testing.T) {
+	dir := t.TempDir()
+	legacyFile := filepath.Join(dir, "config.txt")
+	writeFile(t, legacyFile, "2 3\nabc\n")
+
+	if _, err := LoadConfigFile(filepath.Join(dir, "config.json")); err == nil {
+		t.Fatal("LoadConfigFile succeeded, want error")
+	}
+
+	if !fileExists(legacyFile) || fileExists(filepath.Join(dir, "config.json")) {
+		t.Error("broken legacy file was migrated")
+	}
+}
+
+func TestLoadConfigFileMissing(t *testing.T) {
+	if _, err := LoadConfigFile(filepath.Join(t.TempDir(), "config.json")); err == nil {
+		t.Error("LoadConfigFile succeeded for a missing explicit file, want error")
+	}
+}
+
+func TestPathMapsLegacyFile(t *testing.T) {
+	path, err := Path("conf/config.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "conf/config.json" {
+		t.Errorf("Path(conf/config.txt) = %q, want conf/config.json", path)
+	}
+}
+
+func checkLegacyConfig(t *testing.T, height, width int, chars string, separator rune, passThrough bool) {
+	t.Helper()
+	if height != 2 || width != 3 || chars != "abcdef" || separator != 'f' || !passThrough {
+		t.Errorf("config = %dx%d %q separator %q pass-through %v, want 2x3 \"abcdef\" separator 'f' pass-through true",
+			height, width, chars, separator, passThrough)
+	}
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+const (
+	passThroughOption   = "passthrough"
+	strictFillersOption = "strict"
+	padFillerOption     = "pad"
+	altFillerOption     = "alt"
+)
+
+func isLegacyText(data []byte) bool {
+	trimmed := strings.TrimLeftFunc(string(data), unicode.IsSpace)
+	return !strings.HasPrefix(trimmed, "{")
+}
+
+func legacyPath(confFile string) string {
+	base, ok := strings.CutSuffix(confFile, ".json")
+	if !ok {
+		return ""
+	}
+
+	return base + ".txt"
+}
+
+// executableLegacyPath is where the first releases kept the config file.
+func executableLegacyPath() string {
+	execPath, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(execPath, "..", "..", "config", "config.txt")
+}
+
+func loadLegacyText(cfgText string) (model.Config, error) {
+	c := model.Config{}
+
+	lines := strings.Split(cfgText, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimFunc(line, func(r rune) bool {
+			return !unicode.IsGraphic(r)
+		})
+	}
+
+	if len(lines) <= 2 {
+		return model.Config{}, fmt.Errorf("incorrect config file")
+	}
+
+	_, err := fmt.Sscanf(lines[0], "%d %d", &c.Height, &c.Width)
+	if err != nil {
+		return model.Config{}, fmt.Errorf("can't read matrix height and width from config file: %w", err)
+	}
+
+	if c.Height < 1 {
+		return model.Config{}, fmt.Errorf("incorrect height value")
+	}
+
+	if c.Width < 1 {
+		return model.Config{}, fmt.Errorf("incorrect width valuee")
+	}
+
+	if len(lines) < c.Height+2 {
+		return model.Config{}, fmt.Errorf("incorrext config file")
+	}
+
+	for _, line := range lines[1 : c.Height+1] {
+		c.Chars = append(c.Chars, []rune(line)...)
+	}
+
+	if len(c.Chars) != c.Height*c.Width {
+		return model.Config{}, fmt.Errorf("incorrext matrix")
+	}
+
+	var idx int
+	_, err = fmt.Sscanf(lines[c.Height+1], "%d", &idx)
+	if err != nil {
+		return model.Config{}, fmt.Errorf("can't read separator position in matrix from config file: %w", err)
+	}
+
+	if !(0 <= idx && idx < len(c.Chars)) {
+		return model.Config{}, fmt.Errorf("incorrect separator position in matrix from config file")
+	}
+
+	c.Separator = &(c.Chars[idx])
+
+	for _, line := range lines[c.Height+2:] {
+		option, arg, _ := strings.Cut(line, " ")
+		switch option {
+		case "":
+		case passThroughOption:
+			c.PassThrough = true
+		case strictFillersOption:
+			c.StrictFillers = true
+		case padFillerOption, altFillerOption:
+			idx, err := strconv.Atoi(arg)
+			if err != nil || !(0 <= idx && idx < len(c.Chars)) {
+				return model.Config{}, fmt.Errorf("incorrect %s filler position in matrix from config file", option)
+			}
+
+			if option == padFillerOption {
+				c.PadFiller = &(c.Chars[idx])
+			} else {
+				c.AltFiller = &(c.Chars[idx])
+			}
+		default:
+			return model.Config{}, fmt.Errorf("unknown option '%s' in config file", line)
+		}
+	}
+
+	return c, nil
+}
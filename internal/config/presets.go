@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+type Preset struct {
+	Name        string
+	Description string
+	Algorithm   model.Algorithm
+	Height      int
+	Width       int
+	Depth       int
+	Chars       string
+	Separator   rune
+	Merges      map[rune]rune
+}
+
+var presets = []Preset{
+	{
+		Name:        "en-5x5",
+		Description: "classic English, I/J merged",
+		Height:      5,
+		Width:       5,
+		Chars:       "abcdefghiklmnopqrstuvwxyz",
+		Separator:   'x',
+		Merges:      map[rune]rune{'j': 'i'},
+	},
+	{
+		Name:        "en-6x6",
+		Description: "English letters and digits",
+		Height:      6,
+		Width:       6,
+		Chars:       "abcdefghijklmnopqrstuvwxyz0123456789",
+		Separator:   'x',
+	},
+	{
+		Name:        "en-4x9",
+		Description: "lowercase English with space and punctuation",
+		Height:      4,
+		Width:       9,
+		Chars:       "abcdefghijklmnopqrstuvwxyz .,!:-()?#",
+		Separator:   '#',
+	},
+	{
+		Name:        "ru-4x8",
+		Description: "Russian, Ё merged into Е",
+		Height:      4,
+		Width:       8,
+		Chars:       "абвгдежзийклмнопрстуфхцчшщъыьэюя",
+		Separator:   'х',
+		Merges:      map[rune]rune{'ё': 'е'},
+	},
+	{
+		Name:        "ru-6x6",
+		Description: "Russian with space and punctuation, Ё merged into Е",
+		Height:      6,
+		Width:       6,
+		Chars:       "абвгдежзийклмнопрстуфхцчшщъыьэюя .,-",
+		Separator:   '-',
+		Merges:      map[rune]rune{'ё': 'е'},
+	},
+	{
+		Name:        "en-4x4x4",
+		Description: "cube of English letters, digits and punctuation",
+		Algorithm:   model.Cube,
+		Height:      4,
+		Width:       4,
+		Depth:       4,
+		Chars:       "abcdefghijklmnopqrstuvwxyz0123456789 .,!?:;-'\"()[]/&%+=*@#$_<>~^",
+		Separator:   '#',
+	},
+}
+
+func Presets() []Preset {
+	return presets
+}
+
+func LookupPreset(name string) (Preset, error) {
+	for _, p := range presets {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	names := make([]string, 0, len(presets))
+	for _, p := range presets {
+		names = append(names, p.Name)
+	}
+
+	return Preset{}, fmt.Errorf("unknown preset '%s', available: %s", name, strings.Join(names, ", "))
+}
+
+func (p Preset) Config() model.Config {
+	sep := p.Separator
+
+	var merges map[rune]rune
+	if len(p.Merges) > 0 {
+		merges = make(map[rune]rune, len(p.Merges))
+		for from, to := range p.Merges {
+			merges[from] = to
+		}
+	}
+
+	return model.Config{
+		Algorithm: p.Algorithm,
+		Height:    p.Height,
+		Width:     p.Width,
+		Depth:     p.Depth,
+		Chars:     []rune(p.Chars),
+		Separator: &sep,
+		Merges:    merges,
+	}
+}
+
+func (p Preset) Size() string {
+	if p.Depth > 0 {
+		return fmt.Sprintf("%dx%dx%d", p.Height, p.Width, p.Depth)
+	}
+
+	return fmt.Sprintf("%dx%d", p.Height, p.Width)
+}
+
+func FormatMerges(merges map[rune]rune) string {
+	pairs := make([]string, 0, len(merges))
+	for from, to := range merges {
+		pairs = append(pairs, fmt.Sprintf("%c→%c", from, to))
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, " ")
+}
@@ -0,0 +1,192 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+const (
+	profilesDir     = "profiles"
+	profileExt      = ".json"
+	defaultProfFile = "default-profile"
+)
+
+var profileNameRe = regexp.MustCompile(`^[\pL\pN_.-]+$`)
+
+type Profiles struct {
+	dir string
+}
+
+func NewProfiles(confDir string) Profiles {
+	return Profiles{dir: confDir}
+}
+
+func (p Profiles) path(name string) string {
+	return filepath.Join(p.dir, profilesDir, name+profileExt)
+}
+
+func ValidateProfileName(name string) error {
+	if !profileNameRe.MatchString(name) || strings.HasPrefix(name, ".") || strings.Contains(name, "..") ||
+		strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("incorrect profile name '%s'", name)
+	}
+
+	return nil
+}
+
+func (p Profiles) Exists(name string) bool {
+	if ValidateProfileName(name) != nil {
+		return false
+	}
+
+	for _, path := range []string{p.path(name), legacyPath(p.path(name))} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p Profiles) List() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(p.dir, profilesDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	seen := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), profileExt)
+		if !ok {
+			name, ok = strings.CutSuffix(entry.Name(), ".txt")
+		}
+
+		if _, dup := seen[name]; !ok || dup || entry.IsDir() {
+			continue
+		}
+
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+func (p Profiles) Load(name string) (model.Config, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return model.Config{}, err
+	}
+
+	if !p.Exists(name) {
+		return model.Config{}, fmt.Errorf("profile '%s' not found", name)
+	}
+
+	return LoadConfigFile(p.path(name))
+}
+
+func (p Profiles) Save(name string, c model.Config) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	return CreateConfigFile(p.path(name), c)
+}
+
+func (p Profiles) Rename(oldName, newName string) error {
+	if err := ValidateProfileName(oldName); err != nil {
+		return err
+	}
+
+	if err := ValidateProfileName(newName); err != nil {
+		return err
+	}
+
+	if !p.Exists(oldName) {
+		return fmt.Errorf("profile '%s' not found", oldName)
+	}
+
+	if p.Exists(newName) {
+		return fmt.Errorf("profile '%s' already exists", newName)
+	}
+
+	if _, err := p.Load(oldName); err != nil {
+		return err
+	}
+
+	if err := os.Rename(p.path(oldName), p.path(newName)); err != nil {
+		return err
+	}
+
+	if def, _ := p.Default(); def == oldName {
+		return p.SetDefault(newName)
+	}
+
+	return nil
+}
+
+func (p Profiles) Delete(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	if !p.Exists(name) {
+		return fmt.Errorf("profile '%s' not found", name)
+	}
+
+	for _, path := range []string{p.path(name), legacyPath(p.path(name))} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	if def, _ := p.Default(); def == name {
+		return p.SetDefault("")
+	}
+
+	return nil
+}
+
+func (p Profiles) Default() (string, error) {
+	data, err := os.ReadFile(filepath.Join(p.dir, defaultProfFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (p Profiles) SetDefault(name string) error {
+	defFile := filepath.Join(p.dir, defaultProfFile)
+	if name == "" {
+		if err := os.Remove(defFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+
+	if !p.Exists(name) {
+		return fmt.Errorf("profile '%s' not found", name)
+	}
+
+	return os.WriteFile(defFile, []byte(name+"\n"), 0644)
+}
@@ -0,0 +1,267 @@
+package crack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/internal/ngram"
+)
+
+const (
+	defaultSteps      = 100
+	defaultIterations = 20000
+)
+
+type Options struct {
+	Height, Width int
+	Rules         model.Rules
+	Model         *ngram.Model
+
+	Steps       int     // temperature steps, default 100
+	Iterations  int     // candidates tried at each temperature, default 20000
+	Restarts    int     // independent runs, default 1
+	Temperature float64 // start temperature, default scaled by text length
+	Seed        uint64  // 0 picks a random seed
+
+	// Progress is called after every temperature step.
+	Progress func(Progress)
+}
+
+type Progress struct {
+	Restart, Restarts int
+	Step, Steps       int
+	Temperature       float64
+	Score             float64 // best score per n-gram so far
+	Key               []rune
+}
+
+// Result is the best matrix found, written row by row.
+type Result struct {
+	Key   []rune
+	Score float64 // per n-gram
+}
+
+// Anneal searches for the Playfair matrix over the model alphabet that
+// deciphers cipherText into the most likely text under the model. It stops
+// early when ctx is done and returns the best matrix found so far together
+// with the context error.
+func Anneal(ctx context.Context, cipherText []rune, opts Options) (Result, error) {
+	if opts.Model == nil {
+		return Result{}, errors.New("[model] must be set")
+	}
+
+	chars := opts.Model.Chars()
+	if opts.Height*opts.Width != len(chars) {
+		return Result{}, fmt.Errorf("alphabet length %d doesn't match %dx%d matrix", len(chars), opts.Height, opts.Width)
+	}
+
+	text := make([]int, len(cipherText))
+	for i, char := range cipherText {
+		idx, ok := opts.Model.Index(char)
+		if !ok {
+			return Result{}, fmt.Errorf("char '%c' at %d not in alphabet", char, i)
+		}
+		text[i] = idx
+	}
+
+	if len(text) == 0 || len(text)%2 != 0 {
+		return Result{}, errors.New("ciphertext length must be even and non-zero")
+	}
+
+	opts.setDefaults(len(text))
+
+	s := &search{
+		opts:   opts,
+		text:   text,
+		plain:  make([]int, len(text)),
+		pos:    make([]int, len(chars)),
+		grams:  float64(max(opts.Model.Count(text), 1)),
+		height: opts.Height,
+		width:  opts.Width,
+	}
+	s.rowShift, s.columnShift = opts.Rules.Shifts()
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15))
+
+	best := Result{Score: math.Inf(-1)}
+	for restart := 1; restart <= opts.Restarts; restart++ {
+		res, err := s.run(ctx, rng, restart, &best)
+		if res.Score > best.Score {
+			best = res
+		}
+		if err != nil {
+			return best, err
+		}
+	}
+
+	return best, nil
+}
+
+func (o *Options) setDefaults(length int) {
+	if o.Steps <= 0 {
+		o.Steps = defaultSteps
+	}
+
+	if o.Iterations <= 0 {
+		o.Iterations = defaultIterations
+	}
+
+	if o.Restarts <= 0 {
+		o.Restarts = 1
+	}
+
+	if o.Temperature <= 0 {
+		// grows with the text since scores are sums over all n-grams
+		o.Temperature = max(0.025*float64(length), 1)
+	}
+
+	if o.Seed == 0 {
+		o.Seed = rand.Uint64()
+	}
+}
+
+type search struct {
+	opts  Options
+	text  []int
+	plain []int
+	pos   []int // cell of every alphabet index, rebuilt by score
+	grams float64
+
+	height, width         int
+	rowShift, columnShift int
+}
+
+func (s *search) run(ctx context.Context, rng *rand.Rand, restart int, best *Result) (Result, error) {
+	size := s.height * s.width
+
+	cur := rng.Perm(size)
+	curScore := s.score(cur)
+
+	top := append([]int(nil), cur...)
+	topScore := curScore
+
+	cand := make([]int, size)
+	for step := 0; step < s.opts.Steps; step++ {
+		temp := s.opts.Temperature * float64(s.opts.Steps-step) / float64(s.opts.Steps)
+
+		for i := 0; i < s.opts.Iterations; i++ {
+			if i%1000 == 0 && ctx.Err() != nil {
+				return s.result(top, topScore), ctx.Err()
+			}
+
+			copy(cand, cur)
+			s.mutate(rng, cand)
+
+			score := s.score(cand)
+			delta := score - curScore
+			if delta >= 0 || rng.Float64() < math.Exp(delta/temp) {
+				cur, cand = cand, cur
+				curScore = score
+
+				if curScore > topScore {
+					copy(top, cur)
+					topScore = curScore
+				}
+			}
+		}
+
+		if s.opts.Progress != nil {
+			progress := s.result(top, topScore)
+			if best.Score > progress.Score {
+				progress = *best
+			}
+
+			s.opts.Progress(Progress{
+				Restart:     restart,
+				Restarts:    s.opts.Restarts,
+				Step:        step + 1,
+				Steps:       s.opts.Steps,
+				Temperature: temp,
+				Score:       progress.Score,
+				Key:         progress.Key,
+			})
+		}
+	}
+
+	return s.result(top, topScore), nil
+}
+
+func (s *search) result(grid []int, score float64) Result {
+	chars := s.opts.Model.Chars()
+	key := make([]rune, len(grid))
+	for i, idx := range grid {
+		key[i] = chars[idx]
+	}
+
+	return Result{Key: key, Score: score / s.grams}
+}
+
+// mutate changes grid mostly by swapping two cells, sometimes by swapping or
+// reversing whole rows and columns.
+func (s *search) mutate(rng *rand.Rand, grid []int) {
+	switch n := rng.IntN(50); {
+	case n == 0:
+		i, j := rng.IntN(s.height), rng.IntN(s.height)
+		for k := 0; k < s.width; k++ {
+			grid[i*s.width+k], grid[j*s.width+k] = grid[j*s.width+k], grid[i*s.width+k]
+		}
+	case n == 1:
+		i, j := rng.IntN(s.width), rng.IntN(s.width)
+		for k := 0; k < s.height; k++ {
+			grid[k*s.width+i], grid[k*s.width+j] = grid[k*s.width+j], grid[k*s.width+i]
+		}
+	case n == 2:
+		for i := 0; i < s.height/2; i++ {
+			for k := 0; k < s.width; k++ {
+				a, b := i*s.width+k, (s.height-1-i)*s.width+k
+				grid[a], grid[b] = grid[b], grid[a]
+			}
+		}
+	case n == 3:
+		for k := 0; k < s.height; k++ {
+			for i := 0; i < s.width/2; i++ {
+				a, b := k*s.width+i, k*s.width+s.width-1-i
+				grid[a], grid[b] = grid[b], grid[a]
+			}
+		}
+	default:
+		i, j := rng.IntN(len(grid)), rng.IntN(len(grid))
+		grid[i], grid[j] = grid[j], grid[i]
+	}
+}
+
+func (s *search) score(grid []int) float64 {
+	pos := s.pos
+	for cell, idx := range grid {
+		pos[idx] = cell
+	}
+
+	for k := 0; k+1 < len(s.text); k += 2 {
+		c1, c2 := pos[s.text[k]], pos[s.text[k+1]]
+		i1, j1 := c1/s.width, c1%s.width
+		i2, j2 := c2/s.width, c2%s.width
+
+		switch {
+		case i1 == i2:
+			j1, j2 = move(j1, -s.rowShift, s.width), move(j2, -s.rowShift, s.width)
+		case j1 == j2:
+			i1, i2 = move(i1, -s.columnShift, s.height), move(i2, -s.columnShift, s.height)
+		case s.opts.Rules.Rectangle == model.SameColumn:
+			i1, i2 = i2, i1
+		default:
+			j1, j2 = j2, j1
+		}
+
+		s.plain[k], s.plain[k+1] = grid[i1*s.width+j1], grid[i2*s.width+j2]
+	}
+
+	return s.opts.Model.ScoreIndices(s.plain)
+}
+
+func move(idx, shift, size int) int {
+	return ((idx+shift)%size + size) % size
+}
@@ -0,0 +1,65 @@
+package crack
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/akaspb/playfair-cipher/internal/ngram"
+)
+
+func TestAnnealLargeAlphabet(t *testing.T) {
+	// 17x17 doesn't fit a fixed 256 cell buffer
+	chars := make([]rune, 17*17)
+	for i := range chars {
+		chars[i] = rune(0x4e00 + i)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < 4000; i++ {
+		sb.WriteRune(chars[(i*i+3*i)%len(chars)])
+	}
+
+	model, err := ngram.Train(strings.NewReader(sb.String()), chars, nil, ngram.OrderFor(len(chars)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := Anneal(context.Background(), []rune(sb.String())[:200], Options{
+		Height: 17, Width: 17, Model: model, Steps: 2, Iterations: 10, Seed: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Key) != len(chars) {
+		t.Errorf("key length = %d, want %d", len(res.Key), len(chars))
+	}
+}
+
+func TestAnnealRejectsBadInput(t *testing.T) {
+	chars := []rune("abcdefghiklmnopqrstuvwxyz")
+	model, err := ngram.Train(strings.NewReader("attackatdawn"), chars, nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		cipherText string
+		opts       Options
+	}{
+		"no model":      {cipherText: "abcd", opts: Options{Height: 5, Width: 5}},
+		"size mismatch": {cipherText: "abcd", opts: Options{Height: 4, Width: 5, Model: model}},
+		"odd length":    {cipherText: "abc", opts: Options{Height: 5, Width: 5, Model: model}},
+		"unknown char":  {cipherText: "abcj", opts: Options{Height: 5, Width: 5, Model: model}},
+		"empty text":    {cipherText: "", opts: Options{Height: 5, Width: 5, Model: model}},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Anneal(context.Background(), []rune(tt.cipherText), tt.opts); err == nil {
+				t.Error("Anneal succeeded, want error")
+			}
+		})
+	}
+}
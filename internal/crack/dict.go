@@ -0,0 +1,274 @@
+package crack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"runtime"
+	"slices"
+	"strconv"
+	"sync"
+	"unicode"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/internal/ngram"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+const (
+	defaultTop = 10
+	// candidates scored between progress reports
+	progressEvery = 1000
+)
+
+type DictOptions struct {
+	// Config is the engine tried with every key, its Key is replaced.
+	Config model.Config
+	Model  *ngram.Model
+
+	Workers int // default one per CPU
+	Top     int // candidates returned, default 10
+
+	// Progress is called from a single goroutine as candidates are scored.
+	Progress func(DictProgress)
+}
+
+type DictProgress struct {
+	Tried   int
+	Skipped int   // keys that build no matrix
+	Failed  int   // keys whose matrix fails to decipher the text
+	Err     error // first deciphering error
+	Best    Candidate
+}
+
+// Candidate is a key with the per n-gram score of the sample it deciphers.
+type Candidate struct {
+	Key       string
+	Score     float64
+	Plaintext string
+}
+
+// Dictionary deciphers cipherText with every key and returns the best
+// scoring ones, best first. Keys that give the same plaintext are listed
+// once. Without Config.KDF keys are folded into the alphabet by case and
+// merges. Candidates are deciphered without strict fillers. When no key
+// deciphers the text the first error is returned. It stops early when ctx
+// is done and returns the best keys so far together with the context error.
+func Dictionary(ctx context.Context, cipherText string, keys iter.Seq[string], opts DictOptions) ([]Candidate, error) {
+	if opts.Model == nil {
+		return nil, errors.New("[model] must be set")
+	}
+
+	if cipherText == "" {
+		return nil, errors.New("[cipherText] must be non-empty string")
+	}
+
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.NumCPU()
+	}
+
+	if opts.Top <= 0 {
+		opts.Top = defaultTop
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	todo := make(chan string, 4*opts.Workers)
+	go func() {
+		defer close(todo)
+		for key := range keys {
+			select {
+			case todo <- key:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make(chan result, 4*opts.Workers)
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range todo {
+				results <- try(cipherText, key, opts)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var (
+		top      []Candidate
+		progress DictProgress
+	)
+	for res := range results {
+		progress.Tried++
+		switch {
+		case res.err != nil:
+			progress.Failed++
+			if progress.Err == nil {
+				progress.Err = res.err
+			}
+		case !res.ok:
+			progress.Skipped++
+		default:
+			top = insert(top, res.Candidate, opts.Top)
+		}
+
+		if opts.Progress != nil && progress.Tried%progressEvery == 0 {
+			if len(top) > 0 {
+				progress.Best = top[0]
+			}
+			opts.Progress(progress)
+		}
+	}
+
+	if opts.Progress != nil {
+		if len(top) > 0 {
+			progress.Best = top[0]
+		}
+		opts.Progress(progress)
+	}
+
+	if len(top) == 0 && progress.Err != nil && ctx.Err() == nil {
+		return nil, progress.Err
+	}
+
+	return top, ctx.Err()
+}
+
+type result struct {
+	Candidate
+	ok  bool
+	err error
+}
+
+func try(cipherText, key string, opts DictOptions) result {
+	cfg := opts.Config
+	// a wrong key usually leaves ambiguous fillers, the right one may too
+	cfg.StrictFillers = false
+	if cfg.KDF == nil {
+		var ok bool
+		if key, ok = fold(key, cfg); !ok {
+			return result{}
+		}
+	}
+
+	cfg.Key = key
+	engine, err := playfair.New(cfg)
+	if err != nil {
+		return result{}
+	}
+
+	plainText, err := engine.Decrypt(cipherText)
+	if err != nil {
+		return result{err: fmt.Errorf("key %q: %w", key, err)}
+	}
+
+	return result{Candidate: Candidate{Key: key, Score: opts.Model.Score(plainText), Plaintext: plainText}, ok: true}
+}
+
+// fold writes key with the runes of the alphabet, looking them up in merges
+// and the other case.
+func fold(key string, cfg model.Config) (string, bool) {
+	res := make([]rune, 0, len(key))
+	for _, char := range key {
+		if to, ok := cfg.Merges[char]; ok {
+			char = to
+		}
+
+		found := false
+		for _, c := range []rune{char, unicode.ToLower(char), unicode.ToUpper(char)} {
+			if slices.Contains(cfg.Chars, c) {
+				res, found = append(res, c), true
+				break
+			}
+		}
+
+		if !found {
+			return "", false
+		}
+	}
+
+	return string(res), len(res) > 0
+}
+
+// insert adds c to the best n candidates sorted by score, unless a better
+// key gave the same plaintext.
+func insert(top []Candidate, c Candidate, n int) []Candidate {
+	for i, t := range top {
+		if t.Plaintext != c.Plaintext {
+			continue
+		}
+
+		if t.Score >= c.Score {
+			return top
+		}
+
+		top = slices.Delete(top, i, i+1)
+		break
+	}
+
+	i, _ := slices.BinarySearchFunc(top, c.Score, func(t Candidate, score float64) int {
+		switch {
+		case t.Score > score:
+			return -1
+		case t.Score < score:
+			return 1
+		}
+		return 0
+	})
+
+	if i >= n {
+		return top
+	}
+
+	top = slices.Insert(top, i, c)
+
+	return top[:min(len(top), n)]
+}
+
+// Mutations yields word, its reversal and word followed by every number of
+// up to digits digits, each once.
+func Mutations(word string, reverse bool, digits int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if !yield(word) {
+			return
+		}
+
+		if reversed := reverseString(word); reverse && reversed != word {
+			if !yield(reversed) {
+				return
+			}
+		}
+
+		limit := 1
+		for n := 1; n <= digits; n++ {
+			limit *= 10
+			for k := 0; k < limit; k++ {
+				suffix := strconv.Itoa(k)
+				for len(suffix) < n {
+					suffix = "0" + suffix
+				}
+
+				if !yield(word + suffix) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func reverseString(s string) string {
+	chars := []rune(s)
+	slices.Reverse(chars)
+
+	return string(chars)
+}
@@ -0,0 +1,68 @@
+package crack
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/internal/ngram"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+func TestDictionary(t *testing.T) {
+	separator := 'x'
+	cfg := model.Config{
+		Height: 5, Width: 5, Chars: []rune("abcdefghiklmnopqrstuvwxyz"), Key: "monarchy",
+		Separator: &separator, Merges: map[rune]rune{'j': 'i'},
+	}
+
+	engine, err := playfair.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cipherText, err := engine.Encrypt("itisatruthuniversallyacknowledgedthatasinglemaninpossessionofagoodfortune")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lang, err := ngram.Default(cfg.Chars, cfg.Merges)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the key is folded into the alphabet, "j!nk" has a rune it can't fold
+	keys := slices.Values([]string{"keyword", "MONARCHY", "j!nk", "playfair"})
+
+	var last DictProgress
+	top, err := Dictionary(context.Background(), cipherText, keys, DictOptions{
+		Config:   cfg,
+		Model:    lang,
+		Workers:  2,
+		Progress: func(p DictProgress) { last = p },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(top) != 3 || top[0].Key != "monarchy" {
+		t.Errorf("Dictionary() = %v, want monarchy first of 3", top)
+	}
+
+	if last.Tried != 4 || last.Skipped != 1 || last.Failed != 0 {
+		t.Errorf("progress = %+v, want 4 tried and 1 skipped", last)
+	}
+}
+
+func TestMutations(t *testing.T) {
+	got := slices.Collect(Mutations("ab", true, 1))
+	want := []string{"ab", "ba", "ab0", "ab1", "ab2", "ab3", "ab4", "ab5", "ab6", "ab7", "ab8", "ab9"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Mutations(ab) = %v, want %v", got, want)
+	}
+
+	if got := slices.Collect(Mutations("aa", true, 0)); !slices.Equal(got, []string{"aa"}) {
+		t.Errorf("Mutations(aa) = %v, want [aa]", got)
+	}
+}
@@ -0,0 +1,371 @@
+package crib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/internal/cipher"
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+const (
+	defaultMaxMatrices = 10
+	defaultMaxNodes    = 2000000
+)
+
+// Unknown marks the cells of a Matrix no digraph tells anything about.
+const Unknown rune = 0
+
+// Digraph is a plaintext pair and the ciphertext pair it turned into.
+type Digraph struct {
+	Plain  [2]rune
+	Cipher [2]rune
+}
+
+type Options struct {
+	Chars         []rune
+	Height, Width int
+	Rules         model.Rules
+
+	MaxMatrices int // default 10
+	MaxNodes    int // cells tried before giving up, default 2000000
+}
+
+// Matrix is a partial Playfair matrix with Unknown in the cells left open.
+type Matrix [][]rune
+
+// String writes the matrix by rows with unknown cells as '·' and space as '␣'.
+func (m Matrix) String() string {
+	return m.Format("·")
+}
+
+// Format writes the matrix like String with unknown cells as unknown.
+func (m Matrix) Format(unknown string) string {
+	rows := make([]string, len(m))
+	for i, row := range m {
+		cells := make([]string, len(row))
+		for j, char := range row {
+			switch char {
+			case Unknown:
+				cells[j] = unknown
+			case ' ':
+				cells[j] = "␣"
+			default:
+				cells[j] = string(char)
+			}
+		}
+		rows[i] = strings.Join(cells, " ")
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// Result lists matrices consistent with the digraphs. As rotating the rows or
+// columns of a matrix doesn't change the cipher, the first char placed is
+// always in the top left corner.
+type Result struct {
+	Matrices []Matrix
+	// Complete is set when the search ran to the end, so Matrices holds every
+	// consistent matrix up to rotation.
+	Complete bool
+}
+
+// Pairs aligns the crib with cipherText starting at rune offset, splitting it
+// into digraphs the way encryption does. With an odd offset the first crib
+// rune closes a pair whose other half is unknown and is skipped, as is a last
+// rune without a partner.
+func Pairs(crib, cipherText []rune, offset int, fillers model.Fillers) ([]Digraph, error) {
+	if offset < 0 || offset >= len(cipherText) {
+		return nil, fmt.Errorf("offset %d out of ciphertext", offset)
+	}
+
+	if offset%2 == 1 {
+		crib, offset = crib[min(1, len(crib)):], offset+1
+	}
+
+	pairs, inserted, err := cipher.Digraphs(string(crib), fillers)
+	if err != nil {
+		return nil, err
+	}
+
+	// the fillers after the last crib rune depend on the text that follows
+	last := len(inserted) - 1
+	for last >= 0 && inserted[last] {
+		last--
+	}
+	pairs = pairs[:last+1-(last+1)%2]
+
+	if offset+len(pairs) > len(cipherText) {
+		return nil, fmt.Errorf("crib at offset %d runs past the end of ciphertext", offset)
+	}
+
+	res := make([]Digraph, 0, len(pairs)/2)
+	for k := 0; k < len(pairs); k += 2 {
+		res = append(res, Digraph{
+			Plain:  [2]rune{pairs[k], pairs[k+1]},
+			Cipher: [2]rune{cipherText[offset+k], cipherText[offset+k+1]},
+		})
+	}
+
+	return res, nil
+}
+
+// Solve searches for the partial matrices in which every digraph enciphers as
+// given. It stops at opts.MaxMatrices results, after opts.MaxNodes tries or
+// when ctx is done, returning what was found with Complete unset.
+func Solve(ctx context.Context, digraphs []Digraph, opts Options) (Result, error) {
+	size := opts.Height * opts.Width
+	if size != len(opts.Chars) {
+		return Result{}, fmt.Errorf("alphabet length %d doesn't match %dx%d matrix", len(opts.Chars), opts.Height, opts.Width)
+	}
+
+	if len(digraphs) == 0 {
+		return Result{}, errors.New("[digraphs] must be non-empty")
+	}
+
+	if opts.MaxMatrices <= 0 {
+		opts.MaxMatrices = defaultMaxMatrices
+	}
+
+	if opts.MaxNodes <= 0 {
+		opts.MaxNodes = defaultMaxNodes
+	}
+
+	s := &solver{
+		ctx:   ctx,
+		opts:  opts,
+		index: make(map[rune]int, size),
+		pos:   make([]int, size),
+		grid:  make([]int, size),
+	}
+	s.rowShift, s.columnShift = opts.Rules.Shifts()
+
+	for i, char := range opts.Chars {
+		s.index[char] = i
+		s.pos[i], s.grid[i] = -1, -1
+	}
+
+	for _, d := range digraphs {
+		var g [4]int
+		for k, char := range []rune{d.Plain[0], d.Plain[1], d.Cipher[0], d.Cipher[1]} {
+			idx, ok := s.index[char]
+			if !ok {
+				return Result{}, fmt.Errorf("char '%c' not in alphabet", char)
+			}
+			g[k] = idx
+		}
+
+		if g[0] == g[1] || g[2] == g[3] {
+			return Result{}, fmt.Errorf("digraph '%c%c' -> '%c%c' has a doubled char", d.Plain[0], d.Plain[1], d.Cipher[0], d.Cipher[1])
+		}
+
+		s.digraphs = append(s.digraphs, g)
+	}
+
+	done := make([]bool, len(s.digraphs))
+	s.search(done, len(done))
+	if err := ctx.Err(); err != nil {
+		return s.result, err
+	}
+
+	s.result.Complete = !s.stopped
+
+	return s.result, nil
+}
+
+type solver struct {
+	ctx  context.Context
+	opts Options
+
+	index    map[rune]int
+	digraphs [][4]int // plain 1, plain 2, cipher 1, cipher 2
+	pos      []int    // char -> cell or -1
+	grid     []int    // cell -> char or -1
+	trail    []int    // chars placed, for undo
+
+	rowShift, columnShift int
+
+	nodes   int
+	stopped bool
+	result  Result
+}
+
+func (s *solver) search(done []bool, left int) {
+	if s.stopped {
+		return
+	}
+
+	if left == 0 {
+		s.result.Matrices = append(s.result.Matrices, s.matrix())
+		s.stopped = len(s.result.Matrices) >= s.opts.MaxMatrices
+		return
+	}
+
+	next, known := -1, -1
+	for k, d := range s.digraphs {
+		if done[k] {
+			continue
+		}
+
+		if n := s.placed(d[:]); n > known {
+			next, known = k, n
+		}
+	}
+
+	d := s.digraphs[next]
+	done[next] = true
+	defer func() { done[next] = false }()
+
+	switch {
+	case s.pos[d[0]] >= 0 && s.pos[d[1]] >= 0:
+		s.derive(d[:2], d[2:], s.rowShift, s.columnShift, done, left)
+	case s.pos[d[2]] >= 0 && s.pos[d[3]] >= 0:
+		s.derive(d[2:], d[:2], -s.rowShift, -s.columnShift, done, left)
+	case s.placed(d[2:]) > s.placed(d[:2]):
+		s.branch(d[2:], d[:2], -s.rowShift, -s.columnShift, done, left)
+	default:
+		s.branch(d[:2], d[2:], s.rowShift, s.columnShift, done, left)
+	}
+}
+
+func (s *solver) placed(chars []int) int {
+	n := 0
+	for _, char := range chars {
+		if s.pos[char] >= 0 {
+			n++
+		}
+	}
+
+	return n
+}
+
+// branch tries every free cell for the unplaced chars of from and derives the
+// other side of the digraph from them.
+func (s *solver) branch(from, to []int, rowShift, columnShift int, done []bool, left int) {
+	char := from[0]
+	if s.pos[char] >= 0 {
+		char = from[1]
+	}
+
+	cells := s.freeCells()
+	if len(s.trail) == 0 {
+		cells = cells[:1]
+	}
+
+	for _, cell := range cells {
+		if !s.tick() {
+			return
+		}
+
+		mark := len(s.trail)
+		if s.place(char, cell) {
+			if s.pos[from[0]] >= 0 && s.pos[from[1]] >= 0 {
+				s.derive(from, to, rowShift, columnShift, done, left)
+			} else {
+				s.branch(from, to, rowShift, columnShift, done, left)
+			}
+		}
+		s.undo(mark)
+
+		if s.stopped {
+			return
+		}
+	}
+}
+
+// derive places to where the pair from moves under the given shifts and goes
+// on with the remaining digraphs.
+func (s *solver) derive(from, to []int, rowShift, columnShift int, done []bool, left int) {
+	if !s.tick() {
+		return
+	}
+
+	cell1, cell2 := s.move(s.pos[from[0]], s.pos[from[1]], rowShift, columnShift)
+
+	mark := len(s.trail)
+	if s.place(to[0], cell1) && s.place(to[1], cell2) {
+		s.search(done, left-1)
+	}
+	s.undo(mark)
+}
+
+func (s *solver) tick() bool {
+	s.nodes++
+	if s.nodes > s.opts.MaxNodes || s.nodes%4096 == 0 && s.ctx.Err() != nil {
+		s.stopped = true
+	}
+
+	return !s.stopped
+}
+
+func (s *solver) move(cell1, cell2, rowShift, columnShift int) (_, _ int) {
+	width, height := s.opts.Width, s.opts.Height
+	i1, j1 := cell1/width, cell1%width
+	i2, j2 := cell2/width, cell2%width
+
+	switch {
+	case i1 == i2:
+		j1, j2 = wrap(j1+rowShift, width), wrap(j2+rowShift, width)
+	case j1 == j2:
+		i1, i2 = wrap(i1+columnShift, height), wrap(i2+columnShift, height)
+	case s.opts.Rules.Rectangle == model.SameColumn:
+		i1, i2 = i2, i1
+	default:
+		j1, j2 = j2, j1
+	}
+
+	return i1*width + j1, i2*width + j2
+}
+
+func wrap(idx, size int) int {
+	return (idx%size + size) % size
+}
+
+func (s *solver) place(char, cell int) bool {
+	switch {
+	case s.pos[char] == cell:
+		return true
+	case s.pos[char] >= 0 || s.grid[cell] >= 0:
+		return false
+	}
+
+	s.pos[char], s.grid[cell] = cell, char
+	s.trail = append(s.trail, char)
+
+	return true
+}
+
+func (s *solver) undo(mark int) {
+	for _, char := range s.trail[mark:] {
+		s.grid[s.pos[char]], s.pos[char] = -1, -1
+	}
+
+	s.trail = s.trail[:mark]
+}
+
+func (s *solver) freeCells() []int {
+	cells := make([]int, 0, len(s.grid))
+	for cell, char := range s.grid {
+		if char < 0 {
+			cells = append(cells, cell)
+		}
+	}
+
+	return cells
+}
+
+func (s *solver) matrix() Matrix {
+	m := make(Matrix, s.opts.Height)
+	for i := range m {
+		m[i] = make([]rune, s.opts.Width)
+		for j := range m[i] {
+			if char := s.grid[i*s.opts.Width+j]; char >= 0 {
+				m[i][j] = s.opts.Chars[char]
+			}
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,136 @@
+package crib_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akaspb/playfair-cipher/internal/crib"
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+const latin25 = "abcdefghiklmnopqrstuvwxyz"
+
+func TestPairs(t *testing.T) {
+	fillers := model.Fillers{Double: 'x', Pad: 'x'}
+	cipherText := []rune("abcdefghik")
+
+	tests := []struct {
+		name    string
+		crib    string
+		offset  int
+		fillers model.Fillers
+		want    string
+	}{
+		{name: "even offset", crib: "hide", offset: 2, fillers: fillers, want: "hi>cd de>ef"},
+		{name: "odd offset", crib: "hide", offset: 1, fillers: fillers, want: "id>cd"},
+		{name: "doubled chars", crib: "ll", offset: 0, fillers: fillers, want: "lx>ab"},
+		{name: "odd crib", crib: "hid", offset: 0, fillers: fillers, want: "hi>ab"},
+		{name: "tail marker", crib: "abq", offset: 0, fillers: model.Fillers{Double: 'x', Pad: 'x', Alt: 'q'}, want: "ab>ab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			digraphs, err := crib.Pairs([]rune(tt.crib), cipherText, tt.offset, tt.fillers)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := ""
+			for i, d := range digraphs {
+				if i > 0 {
+					got += " "
+				}
+				got += string(d.Plain[:]) + ">" + string(d.Cipher[:])
+			}
+
+			if got != tt.want {
+				t.Errorf("Pairs(%q, %d) = %s, want %s", tt.crib, tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPairsRejectsBadOffset(t *testing.T) {
+	fillers := model.Fillers{Double: 'x', Pad: 'x'}
+	for _, offset := range []int{-1, 4, 2} {
+		if _, err := crib.Pairs([]rune("hide"), []rune("abcd"), offset, fillers); err == nil {
+			t.Errorf("Pairs at offset %d succeeded, want error", offset)
+		}
+	}
+}
+
+func TestSolveFindsKeyMatrix(t *testing.T) {
+	separator := 'x'
+	engine, err := playfair.New(playfair.Config{
+		Height: 5, Width: 5, Chars: []rune(latin25), Key: "monarchy", Separator: &separator,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plainText := "thequickbrownfoxiumpsoverthelazydog"
+	cipherText, err := engine.Encrypt(plainText)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digraphs, err := crib.Pairs([]rune(plainText), []rune(cipherText), 0, engine.Fillers())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// other matrices may fit the digraphs too, so the search has to finish
+	res, err := crib.Solve(context.Background(), digraphs, crib.Options{
+		Chars: []rune(latin25), Height: 5, Width: 5, MaxMatrices: 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Matrices) == 0 || !res.Complete {
+		t.Fatalf("found %d matrices, complete %v", len(res.Matrices), res.Complete)
+	}
+
+	grid := engine.Grid()
+	for _, m := range res.Matrices {
+		if fitsRotated(m, grid) {
+			return
+		}
+	}
+
+	t.Errorf("no matrix fits the key matrix up to rotation:\n%s", res.Matrices[0])
+}
+
+func TestMatrixFormat(t *testing.T) {
+	m := crib.Matrix{{'a', crib.Unknown}, {' ', 'b'}}
+	if got, want := m.String(), "a ·\n␣ b"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	if got, want := m.Format("?"), "a ?\n␣ b"; got != want {
+		t.Errorf("Format(?) = %q, want %q", got, want)
+	}
+}
+
+// fitsRotated reports whether the known cells of m match grid with its rows
+// and columns rotated.
+func fitsRotated(m crib.Matrix, grid [][]rune) bool {
+	h, w := len(grid), len(grid[0])
+	for dr := 0; dr < h; dr++ {
+	next:
+		for dc := 0; dc < w; dc++ {
+			for i, row := range m {
+				for j, char := range row {
+					if char != crib.Unknown && char != grid[(i+dr)%h][(j+dc)%w] {
+						continue next
+					}
+				}
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,126 @@
+package decipher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+type Cube struct {
+	cube      *[][][]rune
+	positions *map[rune]model.Pos3
+
+	Strict bool
+}
+
+func NewCube(cube *[][][]rune, positions *map[rune]model.Pos3) (*Cube, error) {
+	return &Cube{
+		cube:      cube,
+		positions: positions,
+	}, nil
+}
+
+func (c *Cube) Decode(cipherText string, fillers model.Fillers) (string, error) {
+	if c == nil {
+		return "", errors.New("*Cube instance is nil")
+	}
+
+	if c.cube == nil {
+		return "", errors.New("cube==nil")
+	}
+
+	if c.positions == nil {
+		return "", errors.New("positions==nil")
+	}
+
+	return decode(cipherText, c.Strict, c.trigraphsOf(squareFillers(fillers)))
+}
+
+func (c *Cube) DecodePassThrough(cipherText string, fillers model.Fillers) (string, error) {
+	if c == nil {
+		return "", errors.New("*Cube instance is nil")
+	}
+
+	if c.positions == nil {
+		return "", errors.New("positions==nil")
+	}
+
+	return decodePassThrough(cipherText, c.Strict, c.inGrid, c.trigraphsOf(squareFillers(fillers)))
+}
+
+func (c *Cube) inGrid(char rune) bool {
+	_, ok := (*c.positions)[char]
+	return ok
+}
+
+func (c *Cube) trigraphsOf(fillers model.Fillers) decodeFunc {
+	return func(cipherChars []rune) ([]rune, []int, []int, error) {
+		if len(cipherChars)%3 != 0 {
+			return nil, nil, nil, errors.New("[cipherText] length must be a multiple of 3")
+		}
+
+		res := make([]rune, 0, len(cipherChars))
+		for i := 0; i < len(cipherChars); i += 3 {
+			char1To, char2To, char3To, err := c.procChars(cipherChars[i], cipherChars[i+1], cipherChars[i+2])
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			res = append(res, char1To, char2To, char3To)
+		}
+
+		if fillers == (model.Fillers{}) {
+			return res, nil, nil, nil
+		}
+
+		return res, trigraphFillerPositions(res, fillers), nil, nil
+	}
+}
+
+// trigraphFillerPositions returns offsets of the pads cipher.getTrigraphs
+// could have appended to the last trigraph.
+func trigraphFillerPositions(s []rune, fillers model.Fillers) []int {
+	n := len(s)
+	if n < 3 || s[n-1] != fillers.PadFor(s[n-2]) {
+		return nil
+	}
+
+	if s[n-2] == fillers.PadFor(s[n-3]) {
+		return []int{n - 2, n - 1}
+	}
+
+	return []int{n - 1}
+}
+
+func (c *Cube) procChars(char1, char2, char3 rune) (_, _, _ rune, _ error) {
+	var pos [3]model.Pos3
+	for i, char := range []rune{char1, char2, char3} {
+		p, ok := (*c.positions)[char]
+		if !ok {
+			return 0, 0, 0, fmt.Errorf("char '%c' not found in grid", char)
+		}
+		pos[i] = p
+	}
+
+	depth, height, width := len(*c.cube), len((*c.cube)[0]), len((*c.cube)[0][0])
+	pos1To, pos2To, pos3To := procTrigraph(pos[0], pos[1], pos[2], depth, height, width)
+
+	return c.at(pos1To), c.at(pos2To), c.at(pos3To), nil
+}
+
+func (c *Cube) at(p model.Pos3) rune {
+	return (*c.cube)[p.L()][p.I()][p.J()]
+}
+
+// procTrigraph undoes cipher.procTrigraph.
+func procTrigraph(q1, q2, q3 model.Pos3, depth, height, width int) (_, _, _ model.Pos3) {
+	if q1 == q2 && q2 == q3 {
+		p := model.Pos3{(q1.L() - 1 + depth) % depth, (q1.I() - 1 + height) % height, (q1.J() - 1 + width) % width}
+		return p, p, p
+	}
+
+	return model.Pos3{q1.L(), q3.I(), q2.J()},
+		model.Pos3{q2.L(), q1.I(), q3.J()},
+		model.Pos3{q3.L(), q2.I(), q1.J()}
+}
@@ -3,7 +3,6 @@ package decipher
 import (
 	"errors"
 	"fmt"
-	"log"
 
 	"github.com/akaspb/playfair-cipher/internal/model"
 )
@@ -11,6 +10,9 @@ import (
 type Decipher struct {
 	grid      *[][]rune
 	positions *map[rune]model.Pos
+
+	Strict bool
+	Rules  model.Rules
 }
 
 func New(grid *[][]rune, positions *map[rune]model.Pos) (*Decipher, error) {
@@ -20,78 +22,91 @@ func New(grid *[][]rune, positions *map[rune]model.Pos) (*Decipher, error) {
 	}, nil
 }
 
-func (d *Decipher) Decode(cipherText string, separator rune) (string, error) {
+func (d *Decipher) Decode(cipherText string, fillers model.Fillers) (string, error) {
 	if d == nil {
-		log.Fatal("*Decipher instance is nil")
+		return "", errors.New("*Decipher instance is nil")
 	}
 
 	if d.grid == nil {
-		log.Fatal("grid==nil")
+		return "", errors.New("grid==nil")
 	}
 
 	if d.positions == nil {
-		log.Fatal("positions==nil")
+		return "", errors.New("positions==nil")
 	}
 
-	pairs := []rune(cipherText)
+	return decode(cipherText, d.Strict, pairsOf(fillers, true, d.procChars))
+}
 
-	if len(pairs)%2 == 1 {
-		return "", errors.New("[cipherText] must be even-length string")
+func (d *Decipher) DecodePassThrough(cipherText string, fillers model.Fillers) (string, error) {
+	if d == nil {
+		return "", errors.New("*Decipher instance is nil")
 	}
 
-	height, width := len(*d.grid), len((*d.grid)[0])
-	decipherPairs := make([]rune, 0, len(pairs))
-	for i := 0; i < len(pairs); i += 2 {
-		char1, char2 := pairs[i], pairs[i+1]
-		pos1, ok := (*d.positions)[char1]
-		if !ok {
-			return "", fmt.Errorf("char '%c' not found in grid", char1)
-		}
-
-		pos2, ok := (*d.positions)[char2]
-		if !ok {
-			return "", fmt.Errorf("char '%c' not found in grid", char2)
-		}
-
-		if pos1 == pos2 {
-			return "", errors.New("incorrect ciphered text")
-		}
-
-		pos1To, pos2To := procPair(pos1, pos2, height, width)
-		char1To := (*d.grid)[pos1To.I()][pos1To.J()]
-		char2To := (*d.grid)[pos2To.I()][pos2To.J()]
-
-		decipherPairs = append(decipherPairs, char1To, char2To)
+	if d.grid == nil {
+		return "", errors.New("grid==nil")
+	}
+
+	if d.positions == nil {
+		return "", errors.New("positions==nil")
+	}
+
+	return decodePassThrough(cipherText, d.Strict, d.inGrid, pairsOf(fillers, true, d.procChars))
+}
+
+func (d *Decipher) inGrid(char rune) bool {
+	_, ok := (*d.positions)[char]
+	return ok
+}
+
+func (d *Decipher) procChars(char1, char2 rune) (_, _ rune, _ error) {
+	pos1, ok := (*d.positions)[char1]
+	if !ok {
+		return 0, 0, fmt.Errorf("char '%c' not found in grid", char1)
 	}
 
-	if separator == 0 {
-		return string(decipherPairs), nil
+	pos2, ok := (*d.positions)[char2]
+	if !ok {
+		return 0, 0, fmt.Errorf("char '%c' not found in grid", char2)
 	}
 
-	return string(removeSeparator(decipherPairs, separator)), nil
+	if pos1 == pos2 {
+		return 0, 0, errors.New("incorrect ciphered text")
+	}
+
+	height, width := len(*d.grid), len((*d.grid)[0])
+	pos1To, pos2To := procPair(pos1, pos2, height, width, d.Rules)
+
+	return (*d.grid)[pos1To.I()][pos1To.J()], (*d.grid)[pos2To.I()][pos2To.J()], nil
 }
 
-func procPair(p1, p2 model.Pos, height, width int) (_, _ model.Pos) {
+func procPair(p1, p2 model.Pos, height, width int, rules model.Rules) (_, _ model.Pos) {
+	rowShift, columnShift := rules.Shifts()
+
 	switch {
 	case p1.I() == p2.I():
-		return procHorizontal(p1, p2, width)
+		return procHorizontal(p1, p2, width, rowShift)
 	case p1.J() == p2.J():
-		return procVertical(p1, p2, height)
+		return procVertical(p1, p2, height, columnShift)
 	default:
 	}
 
-	return procRectangle(p1, p2)
+	return procRectangle(p1, p2, rules.Rectangle)
+}
+
+func procHorizontal(p1, p2 model.Pos, width, shift int) (_, _ model.Pos) {
+	return model.Pos{p1.I(), move(p1.J(), -shift, width)}, model.Pos{p2.I(), move(p2.J(), -shift, width)}
 }
 
-func procHorizontal(p1, p2 model.Pos, width int) (_, _ model.Pos) {
-	return model.Pos{p1.I(), (p1.J() - 1 + width) % width}, model.Pos{p2.I(), (p2.J() - 1 + width) % width} // (-1 + X) % X == X-1
+func procVertical(p1, p2 model.Pos, height, shift int) (_, _ model.Pos) {
+	return model.Pos{move(p1.I(), -shift, height), p1.J()}, model.Pos{move(p2.I(), -shift, height), p2.J()}
 }
 
-func procVertical(p1, p2 model.Pos, height int) (_, _ model.Pos) {
-	return model.Pos{(p1.I() - 1 + height) % height, p1.J()}, model.Pos{(p2.I() - 1 + height) % height, p2.J()}
+func move(idx, shift, size int) int {
+	return ((idx+shift)%size + size) % size
 }
 
-func procRectangle(p1, p2 model.Pos) (_, _ model.Pos) {
+func procRectangle(p1, p2 model.Pos, rectangle model.Rectangle) (_, _ model.Pos) {
 	var reversed bool
 	if p1.I() > p2.I() {
 		p1, p2 = p2, p1
@@ -99,22 +114,13 @@ func procRectangle(p1, p2 model.Pos) (_, _ model.Pos) {
 	}
 
 	p1To, p2To := model.Pos{p1.I(), p2.J()}, model.Pos{p2.I(), p1.J()}
+	if rectangle == model.SameColumn {
+		p1To, p2To = p2To, p1To
+	}
+
 	if reversed {
 		return p2To, p1To
 	}
 
 	return p1To, p2To
 }
-
-func removeSeparator(s []rune, sep rune) []rune {
-	res := make([]rune, 0, len(s))
-	for _, char := range s {
-		if char == sep {
-			continue
-		}
-
-		res = append(res, char)
-	}
-
-	return res
-}
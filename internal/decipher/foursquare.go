@@ -0,0 +1,57 @@
+package decipher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+type FourSquare struct {
+	plain          *[][]rune
+	keyedPositions [2]*map[rune]model.Pos
+
+	Strict bool
+}
+
+func NewFourSquare(plain *[][]rune, keyedPositions1, keyedPositions2 *map[rune]model.Pos) (*FourSquare, error) {
+	return &FourSquare{
+		plain:          plain,
+		keyedPositions: [2]*map[rune]model.Pos{keyedPositions1, keyedPositions2},
+	}, nil
+}
+
+func (f *FourSquare) Decode(cipherText string, fillers model.Fillers) (string, error) {
+	if f == nil {
+		return "", errors.New("*FourSquare instance is nil")
+	}
+
+	return decode(cipherText, f.Strict, pairsOf(squareFillers(fillers), false, f.procChars))
+}
+
+func (f *FourSquare) DecodePassThrough(cipherText string, fillers model.Fillers) (string, error) {
+	if f == nil {
+		return "", errors.New("*FourSquare instance is nil")
+	}
+
+	return decodePassThrough(cipherText, f.Strict, f.inGrid, pairsOf(squareFillers(fillers), false, f.procChars))
+}
+
+func (f *FourSquare) inGrid(char rune) bool {
+	_, ok := (*f.keyedPositions[0])[char]
+	return ok
+}
+
+func (f *FourSquare) procChars(char1, char2 rune) (_, _ rune, _ error) {
+	pos1, ok := (*f.keyedPositions[0])[char1]
+	if !ok {
+		return 0, 0, fmt.Errorf("char '%c' not found in grid", char1)
+	}
+
+	pos2, ok := (*f.keyedPositions[1])[char2]
+	if !ok {
+		return 0, 0, fmt.Errorf("char '%c' not found in grid", char2)
+	}
+
+	return (*f.plain)[pos1.I()][pos2.J()], (*f.plain)[pos2.I()][pos1.J()], nil
+}
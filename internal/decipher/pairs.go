@@ -0,0 +1,173 @@
+package decipher
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+type AmbiguousFillersError struct {
+	Offsets []int
+}
+
+func (e *AmbiguousFillersError) Error() string {
+	return fmt.Sprintf("separator may be a filler or part of the text at offsets %v", e.Offsets)
+}
+
+type pairProc func(char1, char2 rune) (_, _ rune, _ error)
+
+// decodeFunc deciphers runes and reports offsets of the fillers a cipher
+// could have inserted and of the ones it surely inserted.
+type decodeFunc func(cipherChars []rune) (_ []rune, ambiguous, certain []int, _ error)
+
+func pairsOf(fillers model.Fillers, splitDoubles bool, proc pairProc) decodeFunc {
+	return func(cipherChars []rune) ([]rune, []int, []int, error) {
+		decipherPairs, err := decodePairs(cipherChars, proc)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if fillers == (model.Fillers{}) {
+			return decipherPairs, nil, nil, nil
+		}
+
+		ambiguous, certain := fillerPositions(decipherPairs, fillers, splitDoubles)
+		return decipherPairs, ambiguous, certain, nil
+	}
+}
+
+func decodePairs(pairs []rune, proc pairProc) ([]rune, error) {
+	if len(pairs)%2 == 1 {
+		return nil, errors.New("[cipherText] must be even-length string")
+	}
+
+	decipherPairs := make([]rune, 0, len(pairs))
+	for i := 0; i < len(pairs); i += 2 {
+		char1To, char2To, err := proc(pairs[i], pairs[i+1])
+		if err != nil {
+			return nil, err
+		}
+
+		decipherPairs = append(decipherPairs, char1To, char2To)
+	}
+
+	return decipherPairs, nil
+}
+
+func decode(cipherText string, strict bool, decodeFn decodeFunc) (string, error) {
+	decipherChars, ambiguous, certain, err := decodeFn([]rune(cipherText))
+	if err != nil {
+		return "", err
+	}
+
+	if strict && len(ambiguous) > 0 {
+		return "", &AmbiguousFillersError{Offsets: ambiguous}
+	}
+
+	return string(removeAt(decipherChars, mergeOffsets(ambiguous, certain))), nil
+}
+
+func decodePassThrough(cipherText string, strict bool, inGrid func(rune) bool, decodeFn decodeFunc) (string, error) {
+	chars := []rune(cipherText)
+	gridChars := make([]rune, 0, len(chars))
+	for _, char := range chars {
+		if inGrid(char) {
+			gridChars = append(gridChars, char)
+		}
+	}
+
+	decipherChars, ambiguous, certain, err := decodeFn(gridChars)
+	if err != nil {
+		return "", err
+	}
+
+	positions := mergeOffsets(ambiguous, certain)
+	res := make([]rune, 0, len(chars))
+	var offsets []int
+	removed := 0
+	k := 0
+	for _, char := range chars {
+		if !inGrid(char) {
+			res = append(res, char)
+			continue
+		}
+
+		if len(positions) > 0 && positions[0] == k {
+			positions = positions[1:]
+			if slices.Contains(ambiguous, k) {
+				offsets = append(offsets, len(res)+removed)
+			}
+			removed++
+			k++
+			continue
+		}
+
+		res = append(res, decipherChars[k])
+		k++
+	}
+
+	if strict && len(offsets) > 0 {
+		return "", &AmbiguousFillersError{Offsets: offsets}
+	}
+
+	return string(res), nil
+}
+
+// fillerPositions returns offsets of fillers that cipher.getPairs could
+// have inserted: between two identical chars of one pair or as the final pad.
+// With a tail marker the pad and the marker are certain.
+func fillerPositions(s []rune, fillers model.Fillers, splitDoubles bool) (ambiguous, certain []int) {
+	n := len(s)
+	for i := 1; i+1 < n; i += 2 {
+		if splitDoubles && s[i+1] == s[i-1] && s[i] == fillers.DoubleFor(s[i-1]) {
+			ambiguous = append(ambiguous, i)
+		}
+	}
+
+	padded := n >= 2 && s[n-1] == fillers.PadFor(s[n-2])
+	marker := fillers.TailMarker()
+	if marker == 0 {
+		if padded {
+			ambiguous = append(ambiguous, n-1)
+		}
+
+		return ambiguous, nil
+	}
+
+	end := n
+	if padded {
+		end--
+	}
+
+	if end > 0 && s[end-1] == marker {
+		certain = append(certain, end-1)
+	}
+
+	if padded {
+		certain = append(certain, n-1)
+	}
+
+	return ambiguous, certain
+}
+
+func mergeOffsets(a, b []int) []int {
+	res := append(slices.Clone(a), b...)
+	slices.Sort(res)
+	return res
+}
+
+func removeAt(s []rune, idxs []int) []rune {
+	res := make([]rune, 0, len(s)-len(idxs))
+	for i, char := range s {
+		if len(idxs) > 0 && idxs[0] == i {
+			idxs = idxs[1:]
+			continue
+		}
+
+		res = append(res, char)
+	}
+
+	return res
+}
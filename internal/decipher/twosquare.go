@@ -0,0 +1,95 @@
+package decipher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+type TwoSquare struct {
+	grids       [2]*[][]rune
+	positions   [2]*map[rune]model.Pos
+	arrangement model.Arrangement
+
+	Strict bool
+}
+
+func NewTwoSquare(
+	grid1, grid2 *[][]rune,
+	positions1, positions2 *map[rune]model.Pos,
+	arrangement model.Arrangement,
+) (*TwoSquare, error) {
+	if arrangement != model.Horizontal && arrangement != model.Vertical {
+		return nil, fmt.Errorf("unknown arrangement '%s'", arrangement)
+	}
+
+	return &TwoSquare{
+		grids:       [2]*[][]rune{grid1, grid2},
+		positions:   [2]*map[rune]model.Pos{positions1, positions2},
+		arrangement: arrangement,
+	}, nil
+}
+
+func (t *TwoSquare) Decode(cipherText string, fillers model.Fillers) (string, error) {
+	if t == nil {
+		return "", errors.New("*TwoSquare instance is nil")
+	}
+
+	return decode(cipherText, t.Strict, pairsOf(squareFillers(fillers), false, t.procChars))
+}
+
+func (t *TwoSquare) DecodePassThrough(cipherText string, fillers model.Fillers) (string, error) {
+	if t == nil {
+		return "", errors.New("*TwoSquare instance is nil")
+	}
+
+	return decodePassThrough(cipherText, t.Strict, t.inGrid, pairsOf(squareFillers(fillers), false, t.procChars))
+}
+
+func squareFillers(fillers model.Fillers) model.Fillers {
+	if fillers.Alt == 0 {
+		fillers.Alt = fillers.Pad
+	}
+
+	return fillers
+}
+
+func (t *TwoSquare) inGrid(char rune) bool {
+	_, ok := (*t.positions[0])[char]
+	return ok
+}
+
+func (t *TwoSquare) procChars(char1, char2 rune) (_, _ rune, _ error) {
+	pos1, ok := (*t.positions[0])[char1]
+	if !ok {
+		return 0, 0, fmt.Errorf("char '%c' not found in grid", char1)
+	}
+
+	pos2, ok := (*t.positions[1])[char2]
+	if !ok {
+		return 0, 0, fmt.Errorf("char '%c' not found in grid", char2)
+	}
+
+	pos1To, pos2To := procTwoSquare(pos1, pos2, t.arrangement)
+
+	return (*t.grids[0])[pos1To.I()][pos1To.J()], (*t.grids[1])[pos2To.I()][pos2To.J()], nil
+}
+
+// procTwoSquare is its own inverse: swapping to the other rectangle corners
+// twice returns the original pair.
+func procTwoSquare(p1, p2 model.Pos, arrangement model.Arrangement) (_, _ model.Pos) {
+	if arrangement == model.Vertical {
+		if p1.J() == p2.J() {
+			return p1, p2
+		}
+
+		return model.Pos{p1.I(), p2.J()}, model.Pos{p2.I(), p1.J()}
+	}
+
+	if p1.I() == p2.I() {
+		return p1, p2
+	}
+
+	return model.Pos{p2.I(), p1.J()}, model.Pos{p1.I(), p2.J()}
+}
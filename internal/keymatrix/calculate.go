@@ -7,12 +7,18 @@ import (
 )
 
 func Calculate(chars []rune, height, width int, key string) (grid [][]rune, positions map[rune]model.Pos, err error) {
+	return CalculateFill(chars, height, width, key, model.FillRows)
+}
+
+// CalculateFill writes the key followed by the rest of chars into the matrix
+// in the order given by fill.
+func CalculateFill(chars []rune, height, width int, key string, fill model.Fill) (grid [][]rune, positions map[rune]model.Pos, err error) {
 	if height < 2 {
 		return nil, nil, errors.New("[height] must be > 1")
 	}
 
 	if width < 2 {
-		return nil, nil, errors.New("[height] must be > 1")
+		return nil, nil, errors.New("[width] must be > 1")
 	}
 
 	count := len(chars)
@@ -28,46 +34,60 @@ func Calculate(chars []rune, height, width int, key string) (grid [][]rune, posi
 		return nil, nil, errors.New("[key] must consist of [chars]")
 	}
 
+	seq, keyLen := keyedSequence(chars, key)
+	if len(seq) < count {
+		return nil, nil, errors.New("some chars are duplicated")
+	}
+
+	if fill == model.FillColumnar {
+		seq = columnar(seq, keyLen, chars)
+	}
+
+	cells, err := fillOrder(fill, height, width)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	grid = make([][]rune, height)
 	for i := 0; i < height; i++ {
 		grid[i] = make([]rune, width)
 	}
 
-	q := 0
 	positions = make(map[rune]model.Pos, count)
+	for q, char := range seq {
+		pos := cells[q]
+		grid[pos.I()][pos.J()] = char
+		positions[char] = pos
+	}
+
+	return
+}
+
+// keyedSequence returns the distinct chars of key followed by the rest of
+// chars, and the number of distinct key chars.
+func keyedSequence(chars []rune, key string) (seq []rune, keyLen int) {
+	seen := make(map[rune]struct{}, len(chars))
+	seq = make([]rune, 0, len(chars))
 	for _, char := range key {
-		_, ok := positions[char]
-		if ok {
+		if _, ok := seen[char]; ok {
 			continue
 		}
 
-		i := q / width
-		j := q % width
-		grid[i][j] = char
-		positions[char] = model.Pos{i, j}
-
-		q++
+		seen[char] = struct{}{}
+		seq = append(seq, char)
 	}
+	keyLen = len(seq)
 
 	for _, char := range chars {
-		_, ok := positions[char]
-		if ok {
+		if _, ok := seen[char]; ok {
 			continue
 		}
 
-		i := q / width
-		j := q % width
-		grid[i][j] = char
-		positions[char] = model.Pos{i, j}
-
-		q++
+		seen[char] = struct{}{}
+		seq = append(seq, char)
 	}
 
-	if len(positions) < count {
-		return nil, nil, errors.New("some chars are duplicated")
-	}
-
-	return
+	return seq, keyLen
 }
 
 func isWordConsistOfChars(chars []rune, word string) bool {
@@ -84,3 +104,36 @@ func isWordConsistOfChars(chars []rune, word string) bool {
 
 	return true
 }
+
+func Plain(chars []rune, height, width int) (grid [][]rune, positions map[rune]model.Pos, err error) {
+	return Calculate(chars, height, width, string(chars))
+}
+
+// CalculateCube fills a height x width x depth cube layer by layer in the
+// same order Calculate fills a matrix.
+func CalculateCube(chars []rune, height, width, depth int, key string) (cube [][][]rune, positions map[rune]model.Pos3, err error) {
+	if depth < 2 {
+		return nil, nil, errors.New("[depth] must be > 1")
+	}
+
+	if height < 2 {
+		return nil, nil, errors.New("[height] must be > 1")
+	}
+
+	grid, flat, err := Calculate(chars, height*depth, width, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cube = make([][][]rune, depth)
+	for l := range cube {
+		cube[l] = grid[l*height : (l+1)*height]
+	}
+
+	positions = make(map[rune]model.Pos3, len(flat))
+	for char, pos := range flat {
+		positions[char] = model.Pos3{pos.I() / height, pos.I() % height, pos.J()}
+	}
+
+	return cube, positions, nil
+}
@@ -0,0 +1,71 @@
+package keymatrix
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	SaltSize = 16
+	seedSize = 32
+)
+
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return salt, nil
+}
+
+// Derive returns the shuffle of chars determined by passphrase and kdf. It is
+// meant to be used as the key of Calculate, which then writes it unchanged.
+func Derive(chars []rune, passphrase string, kdf model.KDF) ([]rune, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("[passphrase] must be non-empty string")
+	}
+
+	if len(kdf.Salt) == 0 {
+		return nil, errors.New("[kdf salt] must be non-empty")
+	}
+
+	kdf = kdf.WithDefaults()
+
+	var (
+		seed []byte
+		err  error
+	)
+
+	switch kdf.Algorithm {
+	case model.PBKDF2:
+		if kdf.Iterations < 1 {
+			return nil, errors.New("[kdf iterations] must be positive")
+		}
+
+		seed = pbkdf2.Key([]byte(passphrase), kdf.Salt, kdf.Iterations, seedSize, sha256.New)
+	case model.Scrypt:
+		seed, err = scrypt.Key([]byte(passphrase), kdf.Salt, kdf.Cost, kdf.BlockSize, kdf.Parallelism, seedSize)
+		if err != nil {
+			return nil, fmt.Errorf("scrypt: %w", err)
+		}
+	case model.Argon2id:
+		if kdf.Iterations < 1 || kdf.Memory < 8 || kdf.Parallelism < 1 || kdf.Parallelism > math.MaxUint8 {
+			return nil, errors.New("argon2id: incorrect cost parameters")
+		}
+
+		seed = argon2.IDKey([]byte(passphrase), kdf.Salt, uint32(kdf.Iterations), kdf.Memory, uint8(kdf.Parallelism), seedSize)
+	default:
+		return nil, fmt.Errorf("unknown kdf '%s'", kdf.Algorithm)
+	}
+
+	return newChaChaGenerator([seedSize]byte(seed)).Permutation(chars)
+}
@@ -0,0 +1,131 @@
+package keymatrix
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+func fillOrder(fill model.Fill, height, width int) ([]model.Pos, error) {
+	switch fill {
+	case "", model.FillRows, model.FillColumnar:
+		return rowsOrder(height, width), nil
+	case model.FillColumns:
+		return transpose(rowsOrder(width, height)), nil
+	case model.FillSpiralCW:
+		return spiralOrder(height, width), nil
+	case model.FillSpiralCCW:
+		return transpose(spiralOrder(width, height)), nil
+	case model.FillSnake:
+		return snakeOrder(height, width), nil
+	case model.FillDiagonal:
+		return diagonalOrder(height, width), nil
+	}
+
+	return nil, fmt.Errorf("unknown fill '%s'", fill)
+}
+
+func rowsOrder(height, width int) []model.Pos {
+	cells := make([]model.Pos, 0, height*width)
+	for i := 0; i < height; i++ {
+		for j := 0; j < width; j++ {
+			cells = append(cells, model.Pos{i, j})
+		}
+	}
+
+	return cells
+}
+
+func transpose(cells []model.Pos) []model.Pos {
+	for q, pos := range cells {
+		cells[q] = model.Pos{pos.J(), pos.I()}
+	}
+
+	return cells
+}
+
+// spiralOrder goes clockwise from the top left corner to the center.
+func spiralOrder(height, width int) []model.Pos {
+	cells := make([]model.Pos, 0, height*width)
+	top, bottom, left, right := 0, height-1, 0, width-1
+	for top <= bottom && left <= right {
+		for j := left; j <= right; j++ {
+			cells = append(cells, model.Pos{top, j})
+		}
+		top++
+
+		for i := top; i <= bottom; i++ {
+			cells = append(cells, model.Pos{i, right})
+		}
+		right--
+
+		if top <= bottom {
+			for j := right; j >= left; j-- {
+				cells = append(cells, model.Pos{bottom, j})
+			}
+			bottom--
+		}
+
+		if left <= right {
+			for i := bottom; i >= top; i-- {
+				cells = append(cells, model.Pos{i, left})
+			}
+			left++
+		}
+	}
+
+	return cells
+}
+
+// snakeOrder goes left to right in even rows and right to left in odd ones.
+func snakeOrder(height, width int) []model.Pos {
+	cells := rowsOrder(height, width)
+	for i := 1; i < height; i += 2 {
+		row := cells[i*width : (i+1)*width]
+		for l, r := 0, width-1; l < r; l, r = l+1, r-1 {
+			row[l], row[r] = row[r], row[l]
+		}
+	}
+
+	return cells
+}
+
+// diagonalOrder goes along the anti-diagonals from the top left corner,
+// each one from its top right end.
+func diagonalOrder(height, width int) []model.Pos {
+	cells := make([]model.Pos, 0, height*width)
+	for d := 0; d < height+width-1; d++ {
+		for i := max(0, d-width+1); i <= min(d, height-1); i++ {
+			cells = append(cells, model.Pos{i, d - i})
+		}
+	}
+
+	return cells
+}
+
+// columnar writes seq in rows as wide as the key and reads the columns in
+// the alphabet order of the key chars heading them.
+func columnar(seq []rune, keyLen int, chars []rune) []rune {
+	order := make(map[rune]int, len(chars))
+	for idx, char := range chars {
+		order[char] = idx
+	}
+
+	columns := make([]int, keyLen)
+	for c := range columns {
+		columns[c] = c
+	}
+	sort.Slice(columns, func(a, b int) bool {
+		return order[seq[columns[a]]] < order[seq[columns[b]]]
+	})
+
+	res := make([]rune, 0, len(seq))
+	for _, c := range columns {
+		for q := c; q < len(seq); q += keyLen {
+			res = append(res, seq[q])
+		}
+	}
+
+	return res
+}
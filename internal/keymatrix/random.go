@@ -0,0 +1,60 @@
+package keymatrix
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	mrand "math/rand/v2"
+)
+
+// Generator shuffles alphabets into random keys.
+type Generator struct {
+	intN func(n int) (int, error)
+}
+
+// NewGenerator returns a Generator backed by crypto/rand.
+func NewGenerator() *Generator {
+	return &Generator{intN: func(n int) (int, error) {
+		v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+		if err != nil {
+			return 0, err
+		}
+
+		return int(v.Int64()), nil
+	}}
+}
+
+// NewSeededGenerator returns a Generator whose keys are fully determined by
+// seed, so the same seed phrase reproduces the same keys.
+func NewSeededGenerator(seed string) *Generator {
+	return newChaChaGenerator(sha256.Sum256([]byte(seed)))
+}
+
+func newChaChaGenerator(seed [32]byte) *Generator {
+	src := mrand.NewChaCha8(seed)
+
+	return &Generator{intN: func(n int) (int, error) {
+		// rejection sampling keeps the result uniform
+		limit := ^uint64(0) - ^uint64(0)%uint64(n)
+		for {
+			if v := src.Uint64(); v < limit {
+				return int(v % uint64(n)), nil
+			}
+		}
+	}}
+}
+
+// Permutation returns chars in a uniformly random order.
+func (g *Generator) Permutation(chars []rune) ([]rune, error) {
+	res := append([]rune(nil), chars...)
+	for i := len(res) - 1; i > 0; i-- {
+		j, err := g.intN(i + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		res[i], res[j] = res[j], res[i]
+	}
+
+	return res, nil
+}
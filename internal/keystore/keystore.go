@@ -0,0 +1,219 @@
+package keystore
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// DefaultName holds the key used without a profile. Profile names can't
+// start with a dot, so it never collides with one.
+const DefaultName = ".default"
+
+// legacyDefaultName held the key used without a profile in older keystores.
+const legacyDefaultName = "default"
+
+const (
+	version = 1
+
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	saltSize = 16
+
+	// limits for the scrypt parameters read from a file
+	maxScryptN = 1 << 20
+	maxScryptR = 32
+	maxScryptP = 16
+)
+
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted keystore")
+
+type fileData struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"`
+	N       int    `json:"n"`
+	R       int    `json:"r"`
+	P       int    `json:"p"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+type Store struct {
+	path   string
+	salt   []byte
+	n      int
+	r      int
+	p      int
+	secret []byte
+	keys   map[string]string
+}
+
+// SecondName is the entry holding the second key of algorithms that use two.
+func SecondName(name string) string {
+	return name + "#2"
+}
+
+// RoundName is the entry holding the key of round n (starting from 2) of a
+// multi-round cipher.
+func RoundName(name string, n int) string {
+	return fmt.Sprintf("%s#r%d", name, n)
+}
+
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func Open(path string, passphrase []byte) (*Store, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("[passphrase] must be non-empty")
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return create(path, passphrase)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var fd fileData
+	if err := json.Unmarshal(raw, &fd); err != nil {
+		return nil, fmt.Errorf("can't read keystore: %w", err)
+	}
+
+	if fd.Version != version {
+		return nil, fmt.Errorf("unsupported keystore version %d", fd.Version)
+	}
+
+	if fd.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported keystore kdf '%s'", fd.KDF)
+	}
+
+	if fd.N < 2 || fd.N > maxScryptN || fd.N&(fd.N-1) != 0 ||
+		fd.R < 1 || fd.R > maxScryptR || fd.P < 1 || fd.P > maxScryptP {
+		return nil, fmt.Errorf("unsupported keystore scrypt parameters n=%d r=%d p=%d", fd.N, fd.R, fd.P)
+	}
+
+	s := &Store{path: path, salt: fd.Salt, n: fd.N, r: fd.R, p: fd.P}
+	if s.secret, err = scrypt.Key(passphrase, s.salt, s.n, s.r, s.p, chacha20poly1305.KeySize); err != nil {
+		return nil, err
+	}
+
+	aead, err := chacha20poly1305.NewX(s.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(fd.Nonce) != aead.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	plain, err := aead.Open(nil, fd.Nonce, fd.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	if err := json.Unmarshal(plain, &s.keys); err != nil {
+		return nil, fmt.Errorf("can't read keystore entries: %w", err)
+	}
+
+	if s.keys == nil {
+		s.keys = map[string]string{}
+	}
+
+	s.copyLegacyDefault()
+
+	return s, nil
+}
+
+// copyLegacyDefault copies the keys older versions kept under "default",
+// which profile "default" shared, to DefaultName.
+func (s *Store) copyLegacyDefault() {
+	if _, ok := s.keys[DefaultName]; ok {
+		return
+	}
+
+	for name, key := range s.keys {
+		if suffix, ok := strings.CutPrefix(name, legacyDefaultName); ok && (suffix == "" || strings.HasPrefix(suffix, "#")) {
+			s.keys[DefaultName+suffix] = key
+		}
+	}
+}
+
+func create(path string, passphrase []byte) (*Store, error) {
+	s := &Store{
+		path: path,
+		salt: make([]byte, saltSize),
+		n:    scryptN,
+		r:    scryptR,
+		p:    scryptP,
+		keys: map[string]string{},
+	}
+
+	if _, err := rand.Read(s.salt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if s.secret, err = scrypt.Key(passphrase, s.salt, s.n, s.r, s.p, chacha20poly1305.KeySize); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Store) Get(name string) (string, bool) {
+	key, ok := s.keys[name]
+	return key, ok
+}
+
+func (s *Store) Set(name, key string) {
+	s.keys[name] = key
+}
+
+func (s *Store) Delete(name string) {
+	delete(s.keys, name)
+}
+
+func (s *Store) Save() error {
+	plain, err := json.Marshal(s.keys)
+	if err != nil {
+		return err
+	}
+
+	aead, err := chacha20poly1305.NewX(s.secret)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	raw, err := json.MarshalIndent(fileData{
+		Version: version,
+		KDF:     "scrypt",
+		N:       s.n,
+		R:       s.r,
+		P:       s.p,
+		Salt:    s.salt,
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, plain, nil),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, raw, 0600)
+}
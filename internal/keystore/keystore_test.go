@@ -0,0 +1,105 @@
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	s, err := Open(path, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Set(DefaultName, "monarchy")
+	s.Set(SecondName("work"), "keyword")
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = Open(path, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{DefaultName: "monarchy", "work#2": "keyword"} {
+		if key, ok := s.Get(name); !ok || key != want {
+			t.Errorf("Get(%q) = %q, %v, want %q", name, key, ok, want)
+		}
+	}
+
+	if _, err := Open(path, []byte("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Open with wrong passphrase error = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestOpenRejectsBadFiles(t *testing.T) {
+	tests := map[string]func(fd *fileData){
+		"huge n":      func(fd *fileData) { fd.N = 1 << 30 },
+		"n not pow 2": func(fd *fileData) { fd.N = 1000 },
+		"zero r":      func(fd *fileData) { fd.R = 0 },
+		"huge p":      func(fd *fileData) { fd.P = 1 << 20 },
+		"short nonce": func(fd *fileData) { fd.Nonce = fd.Nonce[:4] },
+		"other kdf":   func(fd *fileData) { fd.KDF = "argon2id" },
+	}
+
+	for name, corrupt := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys.json")
+			fd := fileData{Version: version, KDF: "scrypt", N: 16, R: 1, P: 1, Salt: []byte("salt"), Nonce: make([]byte, 24)}
+			corrupt(&fd)
+			writeFileData(t, path, fd)
+
+			if _, err := Open(path, []byte("secret")); err == nil {
+				t.Error("Open succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestOpenCopiesLegacyDefault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	s, err := Open(path, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Set(legacyDefaultName, "monarchy")
+	s.Set(SecondName(legacyDefaultName), "keyword")
+	s.Set("defaults", "other")
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err = Open(path, []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+
+	if key, _ := s.Get(DefaultName); key != "monarchy" {
+		t.Errorf("Get(DefaultName) = %q, want monarchy", key)
+	}
+
+	if key, _ := s.Get(SecondName(DefaultName)); key != "keyword" {
+		t.Errorf("Get(SecondName(DefaultName)) = %q, want keyword", key)
+	}
+
+	if _, ok := s.Get(DefaultName + "s"); ok {
+		t.Error("entry \"defaults\" was copied as a default key")
+	}
+}
+
+func writeFileData(t *testing.T, path string, fd fileData) {
+	t.Helper()
+	raw, err := json.Marshal(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, raw, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
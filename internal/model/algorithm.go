@@ -0,0 +1,21 @@
+package model
+
+type Algorithm string
+
+const (
+	Playfair   Algorithm = "playfair"
+	TwoSquare  Algorithm = "two-square"
+	FourSquare Algorithm = "four-square"
+	Cube       Algorithm = "cube"
+)
+
+func (a Algorithm) TwoKeys() bool {
+	return a == TwoSquare || a == FourSquare
+}
+
+type Arrangement string
+
+const (
+	Horizontal Arrangement = "horizontal"
+	Vertical   Arrangement = "vertical"
+)
@@ -1,9 +1,28 @@
 package model
 
 type Config struct {
+	Algorithm   Algorithm
+	SecondKey   string
+	Arrangement Arrangement
+	Rounds      []Round
+	Rules       Rules
+	Fill        Fill
+	KDF         *KDF
+
 	Height    int
 	Width     int
+	Depth     int
 	Chars     []rune
 	Key       string
 	Separator *rune
+	PadFiller *rune
+	AltFiller *rune
+	Merges    map[rune]rune
+
+	FoldCase        bool
+	StripDiacritics bool
+	DropUnknown     bool
+
+	PassThrough   bool
+	StrictFillers bool
 }
@@ -0,0 +1,31 @@
+package model
+
+// Fill is the order in which the key and the rest of the alphabet are
+// written into a matrix.
+type Fill string
+
+const (
+	FillRows      Fill = "rows"
+	FillColumns   Fill = "columns"
+	FillSpiralCW  Fill = "spiral-cw"
+	FillSpiralCCW Fill = "spiral-ccw"
+	FillSnake     Fill = "snake"
+	FillDiagonal  Fill = "diagonal"
+	FillColumnar  Fill = "keyword-columnar"
+)
+
+var Fills = []Fill{FillRows, FillColumns, FillSpiralCW, FillSpiralCCW, FillSnake, FillDiagonal, FillColumnar}
+
+func (f Fill) Valid() bool {
+	if f == "" {
+		return true
+	}
+
+	for _, fill := range Fills {
+		if f == fill {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,33 @@
+package model
+
+type Fillers struct {
+	Double rune
+	Pad    rune
+	Alt    rune
+}
+
+func (f Fillers) DoubleFor(char rune) rune {
+	if char == f.Double {
+		return f.Alt
+	}
+
+	return f.Double
+}
+
+func (f Fillers) PadFor(char rune) rune {
+	if char == f.Pad {
+		return f.Alt
+	}
+
+	return f.Pad
+}
+
+// TailMarker returns the filler appended to a text ending in a filler, so
+// that its pad can be told apart from the text. Zero means no marker.
+func (f Fillers) TailMarker() rune {
+	if f.Alt == f.Pad {
+		return 0
+	}
+
+	return f.Alt
+}
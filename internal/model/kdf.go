@@ -0,0 +1,68 @@
+package model
+
+// KDFAlgorithm is the key derivation function turning a passphrase into the
+// shuffle of a matrix.
+type KDFAlgorithm string
+
+const (
+	PBKDF2   KDFAlgorithm = "pbkdf2"
+	Scrypt   KDFAlgorithm = "scrypt"
+	Argon2id KDFAlgorithm = "argon2id"
+)
+
+var KDFAlgorithms = []KDFAlgorithm{PBKDF2, Scrypt, Argon2id}
+
+func (a KDFAlgorithm) Valid() bool {
+	for _, alg := range KDFAlgorithms {
+		if a == alg {
+			return true
+		}
+	}
+
+	return false
+}
+
+// KDF makes keys passphrases: the derived bytes shuffle the whole alphabet
+// instead of writing the key in front of it. Zero cost parameters take the
+// defaults of the algorithm.
+type KDF struct {
+	Algorithm   KDFAlgorithm
+	Salt        []byte
+	Iterations  int    // pbkdf2 iterations, argon2id passes
+	Memory      uint32 // argon2id memory in KiB
+	Cost        int    // scrypt N
+	BlockSize   int    // scrypt r
+	Parallelism int    // scrypt p, argon2id threads
+}
+
+// WithDefaults fills unset cost parameters of the selected algorithm.
+func (k KDF) WithDefaults() KDF {
+	switch k.Algorithm {
+	case PBKDF2:
+		if k.Iterations == 0 {
+			k.Iterations = 600000
+		}
+	case Scrypt:
+		if k.Cost == 0 {
+			k.Cost = 1 << 15
+		}
+		if k.BlockSize == 0 {
+			k.BlockSize = 8
+		}
+		if k.Parallelism == 0 {
+			k.Parallelism = 1
+		}
+	case Argon2id:
+		if k.Iterations == 0 {
+			k.Iterations = 3
+		}
+		if k.Memory == 0 {
+			k.Memory = 64 * 1024
+		}
+		if k.Parallelism == 0 {
+			k.Parallelism = 4
+		}
+	}
+
+	return k
+}
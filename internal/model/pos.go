@@ -9,3 +9,23 @@ func (p Pos) I() int {
 func (p Pos) J() int {
 	return p[1]
 }
+
+// Pos3 is a position in a cube: layer, row and column.
+type Pos3 [3]int
+
+func (p Pos3) L() int {
+	return p[0]
+}
+
+func (p Pos3) I() int {
+	return p[1]
+}
+
+func (p Pos3) J() int {
+	return p[2]
+}
+
+// Pos returns the position within the layer.
+func (p Pos3) Pos() Pos {
+	return Pos{p[1], p[2]}
+}
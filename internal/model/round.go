@@ -0,0 +1,9 @@
+package model
+
+// Round is an extra Playfair pass applied after the first one. Zero Height
+// and Width mean the dimensions of the first matrix.
+type Round struct {
+	Key    string
+	Height int
+	Width  int
+}
@@ -0,0 +1,31 @@
+package model
+
+type Rectangle string
+
+const (
+	SameRow    Rectangle = "row"
+	SameColumn Rectangle = "column"
+)
+
+// Rules are the details in which Playfair implementations differ. The zero
+// value follows Wheatstone: pairs in one row or column move by one cell
+// right or down, and in a rectangle each char takes the corner in its row.
+type Rules struct {
+	RowShift    int
+	ColumnShift int
+	Rectangle   Rectangle
+}
+
+// Shifts returns the moves of a pair in one row and in one column, 1 when unset.
+func (r Rules) Shifts() (row, column int) {
+	row, column = r.RowShift, r.ColumnShift
+	if row == 0 {
+		row = 1
+	}
+
+	if column == 0 {
+		column = 1
+	}
+
+	return row, column
+}
@@ -0,0 +1,159 @@
+package ngram
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"unicode"
+)
+
+// The built-in tables are trained with cmd ngrams, see README, over the
+// letters, digits and common punctuation of a language, with and without
+// space.
+//
+//go:embed tables/*.ngm
+var builtin embed.FS
+
+// Languages lists the built-in n-gram tables.
+var Languages = []string{"en", "ru"}
+
+// Builtin returns the built-in model of lang projected onto chars. Alphabets
+// with a space get the table trained with spaces, the others the one trained
+// on text with spaces dropped.
+func Builtin(lang string, chars []rune, merges map[rune]rune) (*Model, error) {
+	m, err := loadBuiltin(lang, chars)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Project(chars, merges)
+}
+
+// Default returns the built-in model of the language sharing the most
+// letters with chars, projected onto them.
+func Default(chars []rune, merges map[rune]rune) (*Model, error) {
+	var (
+		best  *Model
+		found int
+	)
+	for _, lang := range Languages {
+		m, err := loadBuiltin(lang, chars)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := m.letters(chars, merges); n > found {
+			best, found = m, n
+		}
+	}
+
+	if best == nil {
+		return nil, errors.New("no built-in n-grams for the alphabet")
+	}
+
+	return best.Project(chars, merges)
+}
+
+func loadBuiltin(lang string, chars []rune) (*Model, error) {
+	if !slices.Contains(Languages, lang) {
+		return nil, fmt.Errorf("unknown language '%s'", lang)
+	}
+
+	name := lang + ".ngm"
+	if slices.Contains(chars, ' ') {
+		name = lang + "-space.ngm"
+	}
+
+	f, err := builtin.Open("tables/" + name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return read(f)
+}
+
+// letters counts the letters of chars the model has n-grams for.
+func (m *Model) letters(chars []rune, merges map[rune]rune) int {
+	target := newModel(chars, 1)
+
+	covered := make(map[int]bool)
+	for _, char := range m.chars {
+		if idx, ok := target.fold(char, merges); ok && unicode.IsLetter(chars[idx]) {
+			covered[idx] = true
+		}
+	}
+
+	return len(covered)
+}
+
+// Project maps the model onto chars the way Train folds text, looking runes
+// up in merges and the other case. N-grams with a rune chars has no place
+// for are dropped, chars the model has no n-grams for fall to the floor.
+func (m *Model) Project(chars []rune, merges map[rune]rune) (*Model, error) {
+	order := min(m.order, OrderFor(len(chars)))
+	if err := checkAlphabet(chars, order); err != nil {
+		return nil, err
+	}
+
+	p := newModel(chars, order)
+	p.floor = m.floor
+
+	mapping := make([]int, len(m.chars))
+	for i, char := range m.chars {
+		if idx, ok := p.fold(char, merges); ok {
+			mapping[i] = idx
+		} else {
+			mapping[i] = -1
+		}
+	}
+
+	p.seen = make([][]gram, order)
+	for o := range p.seen {
+		sums := make(map[int]float64)
+		var total float64
+		for _, g := range m.seen[o] {
+			idx, ok := project(g.index, o+1, mapping, len(m.chars), len(chars))
+			if !ok {
+				continue
+			}
+
+			prob := math.Pow(10, float64(g.prob))
+			sums[idx] += prob
+			total += prob
+		}
+
+		if len(sums) == 0 {
+			return nil, errors.New("no n-grams of the alphabet in the table")
+		}
+
+		for idx, sum := range sums {
+			p.seen[o] = append(p.seen[o], gram{idx, float32(math.Log10(sum / total))})
+		}
+		slices.SortFunc(p.seen[o], func(a, b gram) int {
+			return a.index - b.index
+		})
+	}
+
+	p.build()
+
+	return p, nil
+}
+
+// project maps the table index of an n-gram over from chars to the one over
+// to chars.
+func project(index, n int, mapping []int, from, to int) (int, bool) {
+	res, div := 0, pow(from, n-1)
+	for ; div > 0; div /= from {
+		char := mapping[index/div]
+		if char < 0 {
+			return 0, false
+		}
+
+		res, index = res*to+char, index%div
+	}
+
+	return res, true
+}
@@ -0,0 +1,152 @@
+package ngram
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
+)
+
+// The binary format keeps only the n-grams seen in text, the rest is
+// rebuilt by backoff on load:
+//
+//	magic "PFNG", version byte, order byte, floor as float32 bits (uint32 LE)
+//	uvarint length and UTF-8 bytes of the alphabet
+//	for each order from 1: uvarint count, then per n-gram the uvarint gap
+//	from the previous table index and the uvarint of -log10 P * quantum
+const (
+	magic   = "PFNG"
+	version = 1
+	quantum = 256
+)
+
+// WriteTo writes the model in the compact binary format read by Read.
+func (m *Model) WriteTo(w io.Writer) (int64, error) {
+	cw := &countWriter{w: bufio.NewWriter(w)}
+
+	cw.write([]byte(magic))
+	cw.write([]byte{version, byte(m.order)})
+	cw.write(binary.LittleEndian.AppendUint32(nil, math.Float32bits(m.floor)))
+
+	chars := []byte(string(m.chars))
+	cw.uvarint(uint64(len(chars)))
+	cw.write(chars)
+
+	for _, seen := range m.seen {
+		cw.uvarint(uint64(len(seen)))
+
+		prev := 0
+		for _, g := range seen {
+			cw.uvarint(uint64(g.index - prev))
+			cw.uvarint(uint64(math.Round(-float64(g.prob) * quantum)))
+			prev = g.index
+		}
+	}
+
+	if cw.err != nil {
+		return cw.n, cw.err
+	}
+
+	return cw.n, cw.w.Flush()
+}
+
+type countWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countWriter) write(p []byte) {
+	if cw.err != nil {
+		return
+	}
+
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+func (cw *countWriter) uvarint(v uint64) {
+	cw.write(binary.AppendUvarint(nil, v))
+}
+
+// Read loads a model written by WriteTo.
+func Read(r io.Reader) (*Model, error) {
+	m, err := read(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m.build()
+
+	return m, nil
+}
+
+// read loads the seen n-grams only, enough to project the model.
+func read(r io.Reader) (*Model, error) {
+	br := bufio.NewReader(r)
+
+	head := make([]byte, len(magic)+6)
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	if string(head[:len(magic)]) != magic {
+		return nil, errors.New("not an n-gram table")
+	}
+
+	if v := head[len(magic)]; v != version {
+		return nil, fmt.Errorf("unsupported n-gram table version %d", v)
+	}
+
+	order := int(head[len(magic)+1])
+	floor := math.Float32frombits(binary.LittleEndian.Uint32(head[len(magic)+2:]))
+
+	size, err := binary.ReadUvarint(br)
+	if err != nil || size > 1<<16 {
+		return nil, errors.New("bad alphabet length")
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(br, buf); err != nil || !utf8.Valid(buf) {
+		return nil, errors.New("bad alphabet")
+	}
+
+	chars := []rune(string(buf))
+	if err := checkAlphabet(chars, order); err != nil {
+		return nil, err
+	}
+
+	m := newModel(chars, order)
+	m.floor = floor
+	m.seen = make([][]gram, order)
+	for o := range m.seen {
+		count, err := binary.ReadUvarint(br)
+		table := pow(len(chars), o+1)
+		if err != nil || count > uint64(table) {
+			return nil, fmt.Errorf("bad %d-gram count", o+1)
+		}
+
+		m.seen[o] = make([]gram, count)
+		idx := 0
+		for k := range m.seen[o] {
+			gap, err1 := binary.ReadUvarint(br)
+			q, err2 := binary.ReadUvarint(br)
+			if err := errors.Join(err1, err2); err != nil {
+				return nil, fmt.Errorf("reading %d-grams: %w", o+1, err)
+			}
+
+			if k > 0 && gap == 0 || uint64(idx)+gap >= uint64(table) {
+				return nil, fmt.Errorf("bad %d-gram index", o+1)
+			}
+
+			idx += int(gap)
+			m.seen[o][k] = gram{idx, -float32(q) / quantum}
+		}
+	}
+
+	return m, nil
+}
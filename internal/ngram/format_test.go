@@ -0,0 +1,90 @@
+package ngram
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+const sample = "the quick brown fox jumps over the lazy dog and the cat sat on the mat"
+
+func TestWriteToRead(t *testing.T) {
+	chars := []rune("abcdefghijklmnopqrstuvwxyz ")
+	m, err := Train(strings.NewReader(sample), chars, nil, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	n, err := m.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo() = %d, wrote %d bytes", n, buf.Len())
+	}
+
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Order() != m.Order() || string(got.Chars()) != string(m.Chars()) {
+		t.Fatalf("Read() order %d alphabet %q, want %d %q", got.Order(), string(got.Chars()), m.Order(), string(m.Chars()))
+	}
+
+	// probabilities are stored in steps of 1/quantum of log10
+	for _, text := range []string{"the cat", "zzqx", sample} {
+		want, have := m.Score(text), got.Score(text)
+		if math.Abs(want-have) > float64(len(text))/quantum {
+			t.Errorf("Score(%q) = %v after Read, want %v", text, have, want)
+		}
+	}
+}
+
+func TestReadRejectsBadTables(t *testing.T) {
+	m, err := Train(strings.NewReader(sample), []rune("abcdefghijklmnopqrstuvwxyz "), nil, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	table := buf.Bytes()
+
+	tests := map[string][]byte{
+		"empty":       nil,
+		"bad magic":   append([]byte("PFNX"), table[4:]...),
+		"bad version": append([]byte(magic+"\x09"), table[5:]...),
+		"truncated":   table[:len(table)/2],
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Read(bytes.NewReader(data)); err == nil {
+				t.Error("Read succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestBuiltinTablesLoad(t *testing.T) {
+	for _, lang := range Languages {
+		// an alphabet with a space picks the table that keeps spaces
+		for _, chars := range [][]rune{nil, []rune(" ")} {
+			m, err := loadBuiltin(lang, chars)
+			if err != nil {
+				t.Errorf("loadBuiltin(%s, %q): %v", lang, string(chars), err)
+				continue
+			}
+
+			if m.Order() < 1 || len(m.Chars()) == 0 {
+				t.Errorf("loadBuiltin(%s, %q) order %d alphabet %q", lang, string(chars), m.Order(), string(m.Chars()))
+			}
+		}
+	}
+}
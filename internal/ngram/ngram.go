@@ -0,0 +1,355 @@
+package ngram
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"unicode"
+)
+
+// MaxTableSize bounds the number of n-grams of a model, larger alphabets get
+// a lower order.
+const MaxTableSize = 1 << 22
+
+// unseen marks table entries left for backoff, log probabilities are never
+// positive.
+const unseen = 1
+
+// Model holds the log10 probabilities of the n-grams over an alphabet, from
+// single chars up to its order.
+type Model struct {
+	order  int
+	chars  []rune
+	index  map[rune]int
+	seen   [][]gram    // seen[o] for the (o+1)-grams found in text
+	tables [][]float32 // tables[o] for the (o+1)-grams
+	probs  []float32   // the table of the model order
+	floor  float32
+
+	once             sync.Once
+	random, language float64 // expected scores per n-gram, see Fit
+}
+
+// gram is the log probability of an n-gram seen in text at its table index.
+type gram struct {
+	index int
+	prob  float32
+}
+
+// OrderFor returns the highest order up to quadgrams whose table over size
+// chars fits MaxTableSize.
+func OrderFor(size int) int {
+	order, table := 0, 1
+	for order < 4 && table*size <= MaxTableSize {
+		order++
+		table *= size
+	}
+
+	return order
+}
+
+// Train counts the n-grams of text read from r. Runes missing from chars are
+// looked up in the other case and in merges, whitespace becomes a space if
+// chars has one; any other rune is skipped, as text is dropped before
+// encryption.
+func Train(r io.Reader, chars []rune, merges map[rune]rune, order int) (*Model, error) {
+	if err := checkAlphabet(chars, order); err != nil {
+		return nil, err
+	}
+
+	m := newModel(chars, order)
+
+	// counts[o] holds the counts of the (o+1)-grams
+	counts := make([][]uint32, order)
+	totals := make([]float64, order)
+	for o := range counts {
+		counts[o] = make([]uint32, pow(len(chars), o+1))
+	}
+
+	table := pow(len(chars), order)
+	window, filled, prevSpace := 0, 0, false
+	br := bufio.NewReader(r)
+	for {
+		char, _, err := br.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		idx, ok := m.fold(char, merges)
+		if !ok {
+			continue
+		}
+
+		space := chars[idx] == ' '
+		if space && prevSpace {
+			continue
+		}
+		prevSpace = space
+
+		window = (window*len(chars) + idx) % table
+		filled++
+		for o := 0; o < min(filled, order); o++ {
+			counts[o][window%len(counts[o])]++
+			totals[o]++
+		}
+	}
+
+	if filled < order {
+		return nil, errors.New("no n-grams found in text")
+	}
+
+	m.seen = make([][]gram, order)
+	for o := range counts {
+		for g, count := range counts[o] {
+			if count > 0 {
+				m.seen[o] = append(m.seen[o], gram{g, float32(math.Log10(float64(count) / totals[o]))})
+			}
+		}
+	}
+
+	m.floor = float32(math.Log10(0.01 / totals[0]))
+	m.build()
+
+	return m, nil
+}
+
+func checkAlphabet(chars []rune, order int) error {
+	if len(chars) == 0 {
+		return errors.New("[chars] must be non-empty")
+	}
+
+	if order < 1 || pow(len(chars), order) > MaxTableSize {
+		return fmt.Errorf("order %d is not supported for %d chars", order, len(chars))
+	}
+
+	index := make(map[rune]bool, len(chars))
+	for _, char := range chars {
+		if index[char] {
+			return fmt.Errorf("char '%c' repeats in alphabet", char)
+		}
+		index[char] = true
+	}
+
+	return nil
+}
+
+func newModel(chars []rune, order int) *Model {
+	m := &Model{
+		order: order,
+		chars: append([]rune(nil), chars...),
+		index: make(map[rune]int, len(chars)),
+	}
+
+	for i, char := range chars {
+		m.index[char] = i
+	}
+
+	return m
+}
+
+// build fills the tables from the seen n-grams. An n-gram never seen is
+// estimated from its (n-1)-grams as P(abc) = P(ab) P(bc) / P(b) with a
+// penalty, so that rare but plausible n-grams don't fall to the floor.
+// Nothing scores below the floor.
+func (m *Model) build() {
+	const penalty = -0.4 // log10 of the backoff weight
+
+	size := len(m.chars)
+
+	m.tables = make([][]float32, m.order)
+
+	var lower, lower2 []float32
+	for o := range m.tables {
+		probs := make([]float32, pow(size, o+1))
+		for g := range probs {
+			probs[g] = unseen
+		}
+		for _, seen := range m.seen[o] {
+			probs[seen.index] = max(seen.prob, m.floor)
+		}
+
+		for g, prob := range probs {
+			if prob != unseen {
+				continue
+			}
+
+			switch o {
+			case 0:
+				probs[g] = m.floor
+			case 1:
+				probs[g] = max(penalty+lower[g/size]+lower[g%size], m.floor)
+			default:
+				prefix, suffix := g/size, g%len(lower)
+				probs[g] = max(penalty+lower[prefix]+lower[suffix]-lower2[prefix%len(lower2)], m.floor)
+			}
+		}
+
+		m.tables[o] = probs
+		lower, lower2 = probs, lower
+	}
+
+	m.probs = m.tables[m.order-1]
+}
+
+func (m *Model) fold(char rune, merges map[rune]rune) (int, bool) {
+	if to, ok := merges[char]; ok {
+		char = to
+	}
+
+	for _, c := range []rune{char, unicode.ToLower(char), unicode.ToUpper(char)} {
+		if idx, ok := m.index[c]; ok {
+			return idx, true
+		}
+	}
+
+	if unicode.IsSpace(char) {
+		idx, ok := m.index[' ']
+		return idx, ok
+	}
+
+	return 0, false
+}
+
+func (m *Model) Order() int {
+	return m.order
+}
+
+func (m *Model) Chars() []rune {
+	return m.chars
+}
+
+// Seen returns the number of n-grams of length n found in the training text.
+func (m *Model) Seen(n int) int {
+	if n < 1 || n > m.order {
+		return 0
+	}
+
+	return len(m.seen[n-1])
+}
+
+// Frequencies returns the probability of each char of the alphabet in the
+// language.
+func (m *Model) Frequencies() map[rune]float64 {
+	freqs := make(map[rune]float64, len(m.chars))
+	for i, char := range m.chars {
+		freqs[char] = math.Pow(10, float64(m.tables[0][i]))
+	}
+
+	return freqs
+}
+
+// Index returns the position of char in the alphabet of the model.
+func (m *Model) Index(char rune) (int, bool) {
+	idx, ok := m.index[char]
+	return idx, ok
+}
+
+// ScoreIndices sums the log probabilities of the n-grams of text given as
+// alphabet positions, a negative position breaks the n-grams around it.
+func (m *Model) ScoreIndices(text []int) float64 {
+	var score float32
+
+	window, filled := 0, 0
+	for _, idx := range text {
+		if idx < 0 {
+			filled = 0
+			continue
+		}
+
+		window = (window*len(m.chars) + idx) % len(m.probs)
+		if filled++; filled >= m.order {
+			score += m.probs[window]
+		}
+	}
+
+	return float64(score)
+}
+
+// Score returns the log10 probability of text per n-gram, so that texts of
+// different length compare. Runes outside the alphabet in either case break
+// the n-grams.
+func (m *Model) Score(text string) float64 {
+	indices := m.indices(text)
+
+	grams := m.Count(indices)
+	if grams == 0 {
+		return float64(m.floor)
+	}
+
+	return m.ScoreIndices(indices) / float64(grams)
+}
+
+// Fit places the score of text on a scale from 0 for random chars of the
+// alphabet to 1 for typical text of the language. It returns the number of
+// n-grams scored, the fit of a short text is noisy.
+func (m *Model) Fit(text string) (fit float64, grams int) {
+	indices := m.indices(text)
+	if grams = m.Count(indices); grams == 0 {
+		return 0, 0
+	}
+
+	m.once.Do(m.baselines)
+
+	score := m.ScoreIndices(indices) / float64(grams)
+
+	return (score - m.random) / (m.language - m.random), grams
+}
+
+// baselines computes the expected score per n-gram of random chars and of
+// text drawn from the model.
+func (m *Model) baselines() {
+	var sum, weights, weighted float64
+	for _, p := range m.probs {
+		w := math.Pow(10, float64(p))
+		sum += float64(p)
+		weights += w
+		weighted += w * float64(p)
+	}
+
+	m.random, m.language = sum/float64(len(m.probs)), weighted/weights
+}
+
+func (m *Model) indices(text string) []int {
+	indices := make([]int, 0, len(text))
+	for _, char := range text {
+		idx, ok := m.fold(char, nil)
+		if !ok {
+			idx = -1
+		}
+		indices = append(indices, idx)
+	}
+
+	return indices
+}
+
+// Count returns the number of n-grams ScoreIndices sums for text.
+func (m *Model) Count(text []int) int {
+	var grams, filled int
+	for _, idx := range text {
+		if idx < 0 {
+			filled = 0
+			continue
+		}
+
+		if filled++; filled >= m.order {
+			grams++
+		}
+	}
+
+	return grams
+}
+
+func pow(base, exp int) int {
+	res := 1
+	for ; exp > 0; exp-- {
+		res *= base
+	}
+
+	return res
+}
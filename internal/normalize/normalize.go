@@ -0,0 +1,156 @@
+package normalize
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type Options struct {
+	FoldCase        bool
+	StripDiacritics bool
+	Merges          map[rune]rune
+	DropUnknown     bool
+}
+
+func (o Options) IsZero() bool {
+	return !o.FoldCase && !o.StripDiacritics && len(o.Merges) == 0 && !o.DropUnknown
+}
+
+type Reason string
+
+const (
+	CaseFolded       Reason = "case"
+	DiacriticRemoved Reason = "diacritic"
+	Merged           Reason = "merge"
+	Dropped          Reason = "dropped"
+)
+
+type Change struct {
+	Offset int
+	From   rune
+	To     string
+	Reason Reason
+}
+
+func (c Change) String() string {
+	if c.Reason == Dropped {
+		return fmt.Sprintf("%d: '%c' dropped", c.Offset, c.From)
+	}
+
+	return fmt.Sprintf("%d: '%c'→'%s' (%s)", c.Offset, c.From, c.To, c.Reason)
+}
+
+type Report struct {
+	Changes []Change
+}
+
+func (r Report) String() string {
+	parts := make([]string, len(r.Changes))
+	for i, c := range r.Changes {
+		parts[i] = c.String()
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Text brings text to the alphabet accepted by allowed. Every step only
+// touches runes that allowed rejects, so runes already in the alphabet are
+// kept as is.
+func Text(text string, allowed func(rune) bool, opts Options) (string, Report) {
+	var (
+		sb     strings.Builder
+		report Report
+	)
+
+	for offset, r := range []rune(text) {
+		res, reason := normalizeRune(r, allowed, opts)
+		if reason == "" {
+			sb.WriteRune(r)
+			continue
+		}
+
+		report.Changes = append(report.Changes, Change{Offset: offset, From: r, To: res, Reason: reason})
+		sb.WriteString(res)
+	}
+
+	return sb.String(), report
+}
+
+func normalizeRune(r rune, allowed func(rune) bool, opts Options) (string, Reason) {
+	if allowed(r) {
+		return "", ""
+	}
+
+	if opts.FoldCase {
+		for _, folded := range []rune{unicode.ToLower(r), unicode.ToUpper(r)} {
+			if folded != r && allowed(folded) {
+				return string(folded), CaseFolded
+			}
+		}
+	}
+
+	if opts.StripDiacritics {
+		if stripped := stripDiacritics(r); stripped != string(r) && allAllowed(stripped, allowed, opts) {
+			return foldString(stripped, allowed, opts), DiacriticRemoved
+		}
+	}
+
+	if to, ok := opts.Merges[r]; ok {
+		return string(to), Merged
+	}
+
+	if opts.FoldCase {
+		for _, folded := range []rune{unicode.ToLower(r), unicode.ToUpper(r)} {
+			if to, ok := opts.Merges[folded]; ok {
+				return string(to), Merged
+			}
+		}
+	}
+
+	if opts.DropUnknown {
+		return "", Dropped
+	}
+
+	return "", ""
+}
+
+func stripDiacritics(r rune) string {
+	decomposed := norm.NFKD.String(string(r))
+
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+
+		return r
+	}, decomposed)
+}
+
+func foldString(s string, allowed func(rune) bool, opts Options) string {
+	return strings.Map(func(r rune) rune {
+		if allowed(r) || !opts.FoldCase {
+			return r
+		}
+
+		for _, folded := range []rune{unicode.ToLower(r), unicode.ToUpper(r)} {
+			if allowed(folded) {
+				return folded
+			}
+		}
+
+		return r
+	}, s)
+}
+
+func allAllowed(s string, allowed func(rune) bool, opts Options) bool {
+	for _, r := range foldString(s, allowed, opts) {
+		if !allowed(r) {
+			return false
+		}
+	}
+
+	return s != ""
+}
@@ -0,0 +1,97 @@
+package normalize
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	latin := func(r rune) bool {
+		return strings.ContainsRune("abcdefghiklmnopqrstuvwxyz", r)
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		opts    Options
+		want    string
+		changes string
+	}{
+		{
+			name: "nothing to do",
+			text: "hello",
+			want: "hello",
+		},
+		{
+			name:    "fold case",
+			text:    "Hello",
+			opts:    Options{FoldCase: true},
+			want:    "hello",
+			changes: "0: 'H'→'h' (case)",
+		},
+		{
+			name:    "strip diacritics",
+			text:    "café",
+			opts:    Options{StripDiacritics: true},
+			want:    "cafe",
+			changes: "3: 'é'→'e' (diacritic)",
+		},
+		{
+			name:    "strip diacritics and fold case",
+			text:    "É",
+			opts:    Options{FoldCase: true, StripDiacritics: true},
+			want:    "e",
+			changes: "0: 'É'→'e' (diacritic)",
+		},
+		{
+			name:    "merge",
+			text:    "jam",
+			opts:    Options{Merges: map[rune]rune{'j': 'i'}},
+			want:    "iam",
+			changes: "0: 'j'→'i' (merge)",
+		},
+		{
+			name:    "merge upper case",
+			text:    "Jam",
+			opts:    Options{FoldCase: true, Merges: map[rune]rune{'j': 'i'}},
+			want:    "iam",
+			changes: "0: 'J'→'i' (merge)",
+		},
+		{
+			name:    "drop unknown",
+			text:    "a b!",
+			opts:    Options{DropUnknown: true},
+			want:    "ab",
+			changes: "1: ' ' dropped, 3: '!' dropped",
+		},
+		{
+			name: "keep unknown",
+			text: "a b",
+			want: "a b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, report := Text(tt.text, latin, tt.opts)
+			if got != tt.want {
+				t.Errorf("Text(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+
+			if report.String() != tt.changes {
+				t.Errorf("Text(%q) changes = %q, want %q", tt.text, report, tt.changes)
+			}
+		})
+	}
+}
+
+func TestTextKeepsAllowedRunes(t *testing.T) {
+	allowed := func(r rune) bool {
+		return strings.ContainsRune("aAé", r)
+	}
+
+	got, report := Text("Aé", allowed, Options{FoldCase: true, StripDiacritics: true})
+	if got != "Aé" || len(report.Changes) != 0 {
+		t.Errorf("Text(Aé) = %q, %v, want the text unchanged", got, report)
+	}
+}
@@ -10,7 +10,7 @@ var _ Tab = &About{}
 
 type About struct{}
 
-func (a About) Update(tea.Msg) {}
+func (a About) Update(tea.Msg) tea.Cmd { return nil }
 
 func (a About) View() string {
 	return `Playfair cipher
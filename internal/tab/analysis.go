@@ -0,0 +1,156 @@
+package tab
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/pkg/analysis"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const analysisTop = 6
+
+var columnStyle = lipgloss.NewStyle().Width(28).MarginRight(2)
+
+func NewAnalysis(engine *playfair.Engine) *Analysis {
+	ti := textarea.New()
+	ti.Placeholder = ""
+	ti.SetHeight(6)
+	ti.SetWidth(58)
+	ti.CharLimit = 2000
+	ti.Focus()
+
+	cfg := engine.Config()
+
+	return &Analysis{
+		engine:   engine,
+		chars:    cfg.Chars,
+		expected: analysis.Expected(cfg.Chars, cfg.Merges),
+
+		ti: ti,
+	}
+}
+
+var _ Tab = &Analysis{}
+
+type Analysis struct {
+	engine   *playfair.Engine
+	chars    []rune
+	expected map[rune]float64
+
+	cipherInput bool
+	ti          textarea.Model
+	before      analysis.Report
+	after       analysis.Report
+	err         error
+}
+
+func (a *Analysis) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch keypress := msg.String(); keypress {
+		case "ctrl+v":
+			if buff, err := clipboard.ReadAll(); err == nil {
+				a.ti.SetValue(buff)
+			}
+		case "ctrl+d":
+			a.ti.SetValue("")
+		case "ctrl+o":
+			a.cipherInput = !a.cipherInput
+		default:
+			a.ti, _ = a.ti.Update(msg)
+		}
+	}
+
+	a.err = a.analyze()
+
+	return nil
+}
+
+func (a *Analysis) analyze() error {
+	a.before, a.after = analysis.Report{}, analysis.Report{}
+
+	text := a.ti.Value()
+	if text == "" {
+		return nil
+	}
+
+	crypt := a.engine.Encrypt
+	if a.cipherInput {
+		crypt = a.engine.Decrypt
+	}
+
+	other, err := crypt(text)
+	if err != nil {
+		return err
+	}
+
+	opts := analysis.Options{Alphabet: a.chars, Expected: a.expected}
+	a.before = analysis.Analyze(a.engine.GridText(text), opts)
+	a.after = analysis.Analyze(a.engine.GridText(other), opts)
+
+	return nil
+}
+
+func reportView(title string, r analysis.Report) string {
+	sb := strings.Builder{}
+	sb.WriteString(title + "\n")
+	sb.WriteString(fmt.Sprintf("length          %8d\n", r.Length))
+	sb.WriteString(fmt.Sprintf("IoC             %8.4f\n", r.IndexOfCoincidence))
+	if r.LanguageIC > 0 {
+		sb.WriteString(fmt.Sprintf("language IoC    %8.4f\n", r.LanguageIC))
+		sb.WriteString(fmt.Sprintf("chi-square      %8.1f\n", r.ChiSquare))
+	}
+	sb.WriteString(fmt.Sprintf("doubled pairs   %8d\n", r.DoubledDigraphs))
+	sb.WriteString(fmt.Sprintf("reversed pairs  %8d\n", len(r.ReversedPairs)))
+
+	sb.WriteString("letters:\n" + countsView(r.Letters))
+	sb.WriteString("\ndigraphs:\n" + countsView(r.Digraphs))
+
+	return sb.String()
+}
+
+// countsView lists the most frequent counts in two lines.
+func countsView(counts []analysis.Count) string {
+	counts = counts[:min(analysisTop, len(counts))]
+
+	sb := strings.Builder{}
+	for i, c := range counts {
+		if i == analysisTop/2 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf(" %s %d", strings.ReplaceAll(c.Text, " ", "␣"), c.Count))
+	}
+
+	return sb.String()
+}
+
+func (a *Analysis) View() string {
+	before, after, other := "Plaintext", "Ciphertext", "ciphertext"
+	if a.cipherInput {
+		before, after, other = "Ciphertext", "Deciphered", "plaintext"
+	}
+
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("%s:\n%s\n", before, a.ti.View()))
+
+	if a.err != nil {
+		sb.WriteString(fmt.Sprintf("* %s\n", a.err.Error()))
+	} else {
+		sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+			columnStyle.Render(reportView(before, a.before)),
+			columnStyle.Render(reportView(after, a.after)),
+		))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(`
+    (ctrl+o - switch to ` + other + ` input)
+    (ctrl+v / ctrl+d - paste / clear text)`)
+
+	return sb.String()
+}
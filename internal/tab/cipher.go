@@ -5,15 +5,15 @@ import (
 	"os"
 	"strings"
 
-	"github.com/akaspb/playfair-cipher/internal/cipher"
 	"github.com/akaspb/playfair-cipher/internal/file"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func NewCipher(cipherService *cipher.Cipher, separator *rune) *Cipher {
+func NewCipher(engine *playfair.Engine) *Cipher {
 	fi := textinput.New()
 	fi.Placeholder = "file name with extension"
 	fi.Prompt = "> "
@@ -37,8 +37,7 @@ func NewCipher(cipherService *cipher.Cipher, separator *rune) *Cipher {
 	ti.CharLimit = 500
 
 	return &Cipher{
-		cipherService: cipherService,
-		separator:     separator,
+		engine: engine,
 
 		fi: fi,
 		ti: ti,
@@ -49,19 +48,21 @@ func NewCipher(cipherService *cipher.Cipher, separator *rune) *Cipher {
 var _ Tab = &Cipher{}
 
 type Cipher struct {
-	cipherService *cipher.Cipher
-	separator     *rune
+	engine *playfair.Engine
 
 	fileIsSaved bool
+	showCube    bool
+	normalized  string
 	fi          textinput.Model
 	ti          textarea.Model
 	to          textarea.Model
 	err         error
 }
 
-func (c *Cipher) Update(msg tea.Msg) {
+func (c *Cipher) Update(msg tea.Msg) tea.Cmd {
 	c.err = nil
 	c.fileIsSaved = false
+	c.normalized = ""
 
 	var (
 		ctrlV    = false
@@ -83,6 +84,8 @@ func (c *Cipher) Update(msg tea.Msg) {
 			saveText = true
 		case "ctrl+d":
 			c.ti.SetValue("")
+		case "ctrl+l":
+			c.showCube = !c.showCube && isCube(c.engine)
 		case "up":
 			c.fi.Focus()
 			c.ti.Blur()
@@ -115,10 +118,14 @@ func (c *Cipher) Update(msg tea.Msg) {
 		}
 	}
 
-	ciphered, err := c.cipherService.Code(c.ti.Value(), *c.separator)
+	if _, report := c.engine.Normalize(c.ti.Value()); len(report.Changes) > 0 {
+		c.normalized = reportToText(report)
+	}
+
+	ciphered, err := c.engine.Encrypt(c.ti.Value())
 	if err != nil {
 		c.err = err
-		return
+		return nil
 	}
 
 	c.to.SetValue(ciphered)
@@ -135,6 +142,8 @@ func (c *Cipher) Update(msg tea.Msg) {
 			c.fileIsSaved = true
 		}
 	}
+
+	return nil
 }
 
 func loadFile(fileName string) (string, error) {
@@ -159,6 +168,18 @@ func getWorkingDir() (string, error) {
 	return os.Getwd()
 }
 
+func reportToText(report playfair.NormalizeReport) string {
+	const maxChanges = 4
+
+	if len(report.Changes) <= maxChanges {
+		return fmt.Sprintf("* normalized: %s", report)
+	}
+
+	shown := playfair.NormalizeReport{Changes: report.Changes[:maxChanges]}
+
+	return fmt.Sprintf("* normalized: %s and %d more", shown, len(report.Changes)-maxChanges)
+}
+
 func (c *Cipher) View() string {
 	sb := strings.Builder{}
 	sb.WriteString(c.fi.View())
@@ -166,6 +187,14 @@ func (c *Cipher) View() string {
 		sb.WriteString("* ciphertext saved to file")
 	}
 	sb.WriteString("\n")
+	if c.showCube {
+		sb.WriteString(cubeView(c.engine))
+		sb.WriteString("\n")
+	}
+	if c.normalized != "" {
+		sb.WriteString(c.normalized)
+		sb.WriteString("\n")
+	}
 
 	if c.err != nil {
 		sb.WriteString(fmt.Sprintf(`Your text:
@@ -183,9 +212,10 @@ Ciphertext:
 %s
      (ctrl+v / ctrl+r - load from clipboard / file)
       (ctrl+s / ctrl+w - save to clipboard / file)
-			     (ctrl+d - clear text)`,
+			     (ctrl+d - clear text)%s`,
 			c.ti.View(),
 			c.to.View(),
+			cubeHelp(c.engine),
 		))
 	}
 
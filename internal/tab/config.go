@@ -2,12 +2,14 @@ package tab
 
 import (
 	"fmt"
-	"log"
+	"path/filepath"
 	"strconv"
 
 	configfile "github.com/akaspb/playfair-cipher/internal/config"
 	"github.com/akaspb/playfair-cipher/internal/keymatrix"
+	"github.com/akaspb/playfair-cipher/internal/keystore"
 	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -17,18 +19,30 @@ type inputIdx int
 
 const (
 	keyIn inputIdx = iota
+	key2In
+	roundIn
+	roundSizeIn
+	passIn
 	sepIn
+	padIn
+	altIn
 	abcIn
 	heightIn
 	widthIn
+	depthIn
+	rowShiftIn
+	colShiftIn
+	profIn
 )
 
+var algorithms = []model.Algorithm{model.Playfair, model.TwoSquare, model.FourSquare, model.Cube}
+
 var (
 	focusedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#04c77c"))
 	cursorStyle  = focusedStyle
 )
 
-func NewConfig() *Config {
+func NewConfig(confFile string) (*Config, error) {
 	key := textinput.New()
 	{
 		key.Placeholder = "write your key here"
@@ -45,6 +59,60 @@ func NewConfig() *Config {
 		key.Focus()
 	}
 
+	key2 := textinput.New()
+	{
+		key2.Placeholder = "second key"
+		key2.Prompt = "> "
+		key2.CharLimit = 100
+		key2.Width = 50
+		key2.EchoMode = textinput.EchoPassword
+		key2.EchoCharacter = '*'
+
+		key2.Cursor.Style = cursorStyle
+		key2.PromptStyle = focusedStyle
+		key2.TextStyle = focusedStyle
+	}
+
+	round := textinput.New()
+	{
+		round.Placeholder = "key of an extra round"
+		round.Prompt = "> "
+		round.CharLimit = 100
+		round.Width = 50
+		round.EchoMode = textinput.EchoPassword
+		round.EchoCharacter = '*'
+
+		round.Cursor.Style = cursorStyle
+		round.PromptStyle = focusedStyle
+		round.TextStyle = focusedStyle
+	}
+
+	roundSize := textinput.New()
+	{
+		roundSize.Placeholder = "same as matrix"
+		roundSize.Prompt = "> "
+		roundSize.CharLimit = 5
+		roundSize.Width = 20
+
+		roundSize.Cursor.Style = cursorStyle
+		roundSize.PromptStyle = focusedStyle
+		roundSize.TextStyle = focusedStyle
+	}
+
+	pass := textinput.New()
+	{
+		pass.Placeholder = "keystore passphrase"
+		pass.Prompt = "> "
+		pass.CharLimit = 100
+		pass.Width = 50
+		pass.EchoMode = textinput.EchoPassword
+		pass.EchoCharacter = '*'
+
+		pass.Cursor.Style = cursorStyle
+		pass.PromptStyle = focusedStyle
+		pass.TextStyle = focusedStyle
+	}
+
 	abc := textinput.New()
 	{
 		abc.Placeholder = "alphabet"
@@ -69,6 +137,30 @@ func NewConfig() *Config {
 		sep.TextStyle = focusedStyle
 	}
 
+	pad := textinput.New()
+	{
+		pad.Placeholder = "same as separator"
+		pad.Prompt = "> "
+		pad.CharLimit = 1
+		pad.Width = 20
+
+		pad.Cursor.Style = cursorStyle
+		pad.PromptStyle = focusedStyle
+		pad.TextStyle = focusedStyle
+	}
+
+	alt := textinput.New()
+	{
+		alt.Placeholder = "none"
+		alt.Prompt = "> "
+		alt.CharLimit = 1
+		alt.Width = 20
+
+		alt.Cursor.Style = cursorStyle
+		alt.PromptStyle = focusedStyle
+		alt.TextStyle = focusedStyle
+	}
+
 	width := textinput.New()
 	{
 		width.Placeholder = "XX"
@@ -93,37 +185,148 @@ func NewConfig() *Config {
 		height.TextStyle = focusedStyle
 	}
 
+	depth := textinput.New()
+	{
+		depth.Placeholder = "XX"
+		depth.Prompt = ""
+		depth.CharLimit = 2
+		depth.Width = 2
+
+		depth.Cursor.Style = cursorStyle
+		depth.PromptStyle = focusedStyle
+		depth.TextStyle = focusedStyle
+	}
+
+	rowShift := textinput.New()
+	{
+		rowShift.Placeholder = "1"
+		rowShift.Prompt = ""
+		rowShift.CharLimit = 3
+		rowShift.Width = 3
+
+		rowShift.Cursor.Style = cursorStyle
+		rowShift.PromptStyle = focusedStyle
+		rowShift.TextStyle = focusedStyle
+	}
+
+	colShift := textinput.New()
+	{
+		colShift.Placeholder = "1"
+		colShift.Prompt = ""
+		colShift.CharLimit = 3
+		colShift.Width = 3
+
+		colShift.Cursor.Style = cursorStyle
+		colShift.PromptStyle = focusedStyle
+		colShift.TextStyle = focusedStyle
+	}
+
+	prof := textinput.New()
+	{
+		prof.Placeholder = "none"
+		prof.Prompt = "> "
+		prof.CharLimit = 64
+		prof.Width = 30
+
+		prof.Cursor.Style = cursorStyle
+		prof.PromptStyle = focusedStyle
+		prof.TextStyle = focusedStyle
+	}
+
+	profiles := configfile.NewProfiles(filepath.Dir(confFile))
+	if def, err := profiles.Default(); err == nil {
+		prof.SetValue(def)
+	}
+
 	c := &Config{
+		confFile:  confFile,
+		profiles:  profiles,
+		presetIdx: -1,
 		textInputs: map[inputIdx]*textinput.Model{
-			keyIn:    &key,
-			sepIn:    &sep,
-			abcIn:    &abc,
-			widthIn:  &width,
-			heightIn: &height,
+			keyIn:       &key,
+			key2In:      &key2,
+			roundIn:     &round,
+			roundSizeIn: &roundSize,
+			passIn:      &pass,
+			sepIn:       &sep,
+			padIn:       &pad,
+			altIn:       &alt,
+			abcIn:       &abc,
+			widthIn:     &width,
+			heightIn:    &height,
+			depthIn:     &depth,
+			rowShiftIn:  &rowShift,
+			colShiftIn:  &colShift,
+			profIn:      &prof,
 		},
 		inputIdx: 0,
 	}
 
 	if err := c.loadConfig(); err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	c.Done = make(chan struct{}, 1)
 
-	return c
+	return c, nil
 }
 
 func (c *Config) loadConfig() error {
-	cfg, err := configfile.LoadConfigFile()
+	var (
+		cfg model.Config
+		err error
+	)
+
+	if profile := c.textInputs[profIn].Value(); profile != "" {
+		cfg, err = c.profiles.Load(profile)
+	} else {
+		cfg, err = configfile.LoadConfigFile(c.confFile)
+	}
 	if err != nil {
 		return err
 	}
 
 	c.textInputs[keyIn].SetValue(cfg.Key)
+	c.textInputs[key2In].SetValue(cfg.SecondKey)
+	c.rounds = append([]model.Round(nil), cfg.Rounds...)
+	c.roundIdx = 0
+	c.loadKeys()
+	c.showRound()
 	c.textInputs[sepIn].SetValue(string([]rune{*cfg.Separator}))
+	c.textInputs[padIn].SetValue(runeToText(cfg.PadFiller))
+	c.textInputs[altIn].SetValue(runeToText(cfg.AltFiller))
 	c.textInputs[abcIn].SetValue(string(cfg.Chars))
 	c.textInputs[widthIn].SetValue(strconv.Itoa(cfg.Width))
 	c.textInputs[heightIn].SetValue(strconv.Itoa(cfg.Height))
+	c.textInputs[depthIn].SetValue("")
+	if cfg.Depth > 0 {
+		c.textInputs[depthIn].SetValue(strconv.Itoa(cfg.Depth))
+	}
+	c.textInputs[rowShiftIn].SetValue(shiftToText(cfg.Rules.RowShift))
+	c.textInputs[colShiftIn].SetValue(shiftToText(cfg.Rules.ColumnShift))
+	c.fill = cfg.Fill
+	if c.fill == "" {
+		c.fill = model.FillRows
+	}
+	c.kdf, c.salt = cfg.KDF, nil
+	c.rectangle = cfg.Rules.Rectangle
+	if c.rectangle == "" {
+		c.rectangle = model.SameRow
+	}
+	c.algorithm = cfg.Algorithm
+	if c.algorithm == "" {
+		c.algorithm = model.Playfair
+	}
+	c.arrangement = cfg.Arrangement
+	if c.arrangement == "" {
+		c.arrangement = model.Horizontal
+	}
+	c.passThrough = cfg.PassThrough
+	c.strict = cfg.StrictFillers
+	c.merges = cfg.Merges
+	c.foldCase = cfg.FoldCase
+	c.stripDiacr = cfg.StripDiacritics
+	c.dropUnknown = cfg.DropUnknown
 
 	return nil
 }
@@ -131,16 +334,32 @@ func (c *Config) loadConfig() error {
 var _ Tab = &Config{}
 
 type Config struct {
-	Done       chan struct{}
-	textInputs map[inputIdx]*textinput.Model
-	inputIdx   inputIdx
-	saveRes    string
-	Grid       *[][]rune
-	Positions  *map[rune]model.Pos
-	Separator  *rune
+	Done        chan struct{}
+	confFile    string
+	profiles    configfile.Profiles
+	textInputs  map[inputIdx]*textinput.Model
+	inputIdx    inputIdx
+	algorithm   model.Algorithm
+	arrangement model.Arrangement
+	rounds      []model.Round
+	rectangle   model.Rectangle
+	fill        model.Fill
+	kdf         *model.KDF
+	salt        []byte
+	roundIdx    int
+	passThrough bool
+	strict      bool
+	foldCase    bool
+	stripDiacr  bool
+	dropUnknown bool
+	merges      map[rune]rune
+	presetIdx   int
+	keystore    *keystore.Store
+	saveRes     string
+	Config      model.Config
 }
 
-func (c *Config) Update(msg tea.Msg) {
+func (c *Config) Update(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch keypress := msg.String(); keypress {
@@ -161,9 +380,73 @@ func (c *Config) Update(msg tea.Msg) {
 			}
 
 			c.textInputs[c.inputIdx].Focus()
+		case "ctrl+y":
+			c.algorithm = nextAlgorithm(c.algorithm)
+		case "ctrl+r":
+			if c.arrangement == model.Horizontal {
+				c.arrangement = model.Vertical
+			} else {
+				c.arrangement = model.Horizontal
+			}
+		case "ctrl+q":
+			if c.rectangle == model.SameRow {
+				c.rectangle = model.SameColumn
+			} else {
+				c.rectangle = model.SameRow
+			}
+		case "ctrl+j":
+			c.fill = nextFill(c.fill)
+		case "alt+r":
+			if err := c.randomizeKey(); err != nil {
+				c.saveRes = fmt.Sprintf("* %s", err.Error())
+			} else {
+				c.saveRes = "* random key generated"
+			}
+		case "alt+k":
+			if err := c.nextKDF(); err != nil {
+				c.saveRes = fmt.Sprintf("* %s", err.Error())
+			}
+		case "ctrl+n":
+			if err := c.nextRound(); err != nil {
+				c.saveRes = fmt.Sprintf("* %s", err.Error())
+			}
+		case "ctrl+p":
+			c.passThrough = !c.passThrough
+		case "ctrl+t":
+			c.strict = !c.strict
+		case "ctrl+l":
+			c.foldCase = !c.foldCase
+		case "ctrl+g":
+			c.stripDiacr = !c.stripDiacr
+		case "ctrl+x":
+			c.dropUnknown = !c.dropUnknown
+		case "ctrl+o":
+			if err := c.unlockKeystore(); err != nil {
+				c.saveRes = fmt.Sprintf("* %s", err.Error())
+			} else {
+				c.saveRes = "* keystore unlocked"
+			}
+		case "pgup", "pgdown":
+			if err := c.switchProfile(keypress == "pgdown"); err != nil {
+				c.saveRes = fmt.Sprintf("* %s", err.Error())
+			} else {
+				c.saveRes = "* profile loaded"
+			}
+		case "ctrl+e":
+			preset := c.nextPreset()
+			c.saveRes = fmt.Sprintf("* preset '%s' applied: %s", preset.Name, preset.Description)
+		case "ctrl+f":
+			if err := c.profiles.SetDefault(c.textInputs[profIn].Value()); err != nil {
+				c.saveRes = fmt.Sprintf("* %s", err.Error())
+			} else {
+				c.saveRes = "* default profile set"
+			}
 		case "ctrl+z":
-			c.loadConfig()
-			c.saveRes = "* settings restored"
+			if err := c.loadConfig(); err != nil {
+				c.saveRes = fmt.Sprintf("* %s", err.Error())
+			} else {
+				c.saveRes = "* settings restored"
+			}
 		case "ctrl+s":
 			err := c.saveConfig()
 			if err != nil {
@@ -176,19 +459,52 @@ func (c *Config) Update(msg tea.Msg) {
 
 	model, _ := c.textInputs[c.inputIdx].Update(msg)
 	c.textInputs[c.inputIdx] = &model
+
+	return nil
+}
+
+// randomizeKey fills the focused key field, or the first key when another
+// field is focused, with a random permutation of the alphabet.
+func (c *Config) randomizeKey() error {
+	abc := c.textInputs[abcIn].Value()
+	if err := textFieldValidator(abc, "Alphabet"); err != nil {
+		return err
+	}
+
+	key, err := keymatrix.NewGenerator().Permutation([]rune(abc))
+	if err != nil {
+		return err
+	}
+
+	idx := c.inputIdx
+	if idx != key2In && idx != roundIn {
+		idx = keyIn
+	}
+
+	c.textInputs[idx].SetValue(string(key))
+	return nil
 }
 
 func (c *Config) saveConfig() error {
 	var (
-		key = c.textInputs[keyIn].Value()
-		sep = c.textInputs[sepIn].Value()
-		abc = c.textInputs[abcIn].Value()
+		key  = c.textInputs[keyIn].Value()
+		key2 = c.textInputs[key2In].Value()
+		sep  = c.textInputs[sepIn].Value()
+		abc  = c.textInputs[abcIn].Value()
 	)
 
 	if err := textFieldValidator(key, "Key"); err != nil {
 		return err
 	}
 
+	if c.algorithm.TwoKeys() {
+		if err := textFieldValidator(key2, "Second key"); err != nil {
+			return err
+		}
+	} else {
+		key2 = ""
+	}
+
 	if err := textFieldValidator(sep, "Separator character"); err != nil {
 		return err
 	}
@@ -205,72 +521,499 @@ func (c *Config) saveConfig() error {
 		return err
 	}
 
-	height, _ := strconv.Atoi(c.textInputs[heightIn].Value())
-	width, _ := strconv.Atoi(c.textInputs[widthIn].Value())
+	var depth int
+	if c.algorithm == model.Cube {
+		if err := numFieldValidator(c.textInputs[depthIn].Value(), "Cube depth"); err != nil {
+			return err
+		}
 
-	if _, _, err := keymatrix.Calculate(
-		[]rune(c.textInputs[abcIn].Value()),
-		height,
-		width,
-		c.textInputs[keyIn].Value(),
-	); err != nil {
+		depth, _ = strconv.Atoi(c.textInputs[depthIn].Value())
+	}
+
+	// same-row is the default, so other algorithms get zero rules
+	var rules model.Rules
+	if c.rectangle != model.SameRow {
+		rules.Rectangle = c.rectangle
+	}
+	for _, shift := range []struct {
+		input inputIdx
+		field string
+		dst   *int
+	}{
+		{rowShiftIn, "Row shift", &rules.RowShift},
+		{colShiftIn, "Column shift", &rules.ColumnShift},
+	} {
+		value := c.textInputs[shift.input].Value()
+		if err := shiftFieldValidator(value, shift.field); err != nil {
+			return err
+		}
+		*shift.dst, _ = strconv.Atoi(value)
+	}
+
+	if err := c.storeRound(); err != nil {
 		return err
 	}
 
+	rounds := make([]model.Round, 0, len(c.rounds))
+	for _, r := range c.rounds {
+		if r.Key != "" {
+			rounds = append(rounds, r)
+		}
+	}
+
+	height, _ := strconv.Atoi(c.textInputs[heightIn].Value())
+	width, _ := strconv.Atoi(c.textInputs[widthIn].Value())
+
 	cfg := model.Config{
+		Algorithm:   c.algorithm,
+		SecondKey:   key2,
+		Arrangement: c.arrangement,
+		Rounds:      rounds,
+		Rules:       rules,
+		Fill:        c.fill,
+		KDF:         c.kdf,
+
 		Height:    height,
 		Width:     width,
+		Depth:     depth,
 		Chars:     []rune(abc),
 		Key:       key,
 		Separator: &[]rune(sep)[0],
+		PadFiller: textToRune(c.textInputs[padIn].Value()),
+		AltFiller: textToRune(c.textInputs[altIn].Value()),
+		Merges:    c.merges,
+
+		FoldCase:        c.foldCase,
+		StripDiacritics: c.stripDiacr,
+		DropUnknown:     c.dropUnknown,
+
+		PassThrough:   c.passThrough,
+		StrictFillers: c.strict,
 	}
 
-	if err := configfile.CreateConfigFile(cfg); err != nil {
-		log.Fatal(fmt.Errorf("error during creating config file: %w", err))
+	if _, err := playfair.New(cfg); err != nil {
+		return err
 	}
 
-	grid, positions, err := keymatrix.Calculate(
-		cfg.Chars,
-		cfg.Height,
-		cfg.Width,
-		cfg.Key,
-	)
-	if err != nil {
-		return fmt.Errorf("error during grid making: %w", err)
+	if profile := c.textInputs[profIn].Value(); profile != "" {
+		if err := c.profiles.Save(profile, cfg); err != nil {
+			return fmt.Errorf("error during saving profile: %w", err)
+		}
+	} else if err := configfile.CreateConfigFile(c.confFile, cfg); err != nil {
+		return fmt.Errorf("error during creating config file: %w", err)
+	}
+
+	if c.keystore != nil {
+		c.keystore.Set(c.keyName(), key)
+		if key2 != "" {
+			c.keystore.Set(keystore.SecondName(c.keyName()), key2)
+		}
+		for i, r := range rounds {
+			c.keystore.Set(keystore.RoundName(c.keyName(), i+2), r.Key)
+		}
+		for n := len(rounds) + 2; ; n++ {
+			if _, ok := c.keystore.Get(keystore.RoundName(c.keyName(), n)); !ok {
+				break
+			}
+			c.keystore.Delete(keystore.RoundName(c.keyName(), n))
+		}
+		if err := c.keystore.Save(); err != nil {
+			return fmt.Errorf("error during saving keystore: %w", err)
+		}
 	}
 
-	c.Grid, c.Positions, c.Separator = &grid, &positions, cfg.Separator
+	c.rounds = rounds
+	c.roundIdx = min(c.roundIdx, len(rounds))
+	c.showRound()
+
+	c.Config = cfg
 	c.Done <- struct{}{}
 
 	return nil
 }
 
+func (c *Config) unlockKeystore() error {
+	passphrase := c.textInputs[passIn].Value()
+	if err := textFieldValidator(passphrase, "Keystore passphrase"); err != nil {
+		return err
+	}
+
+	store, err := keystore.Open(configfile.KeystorePath(c.confFile), []byte(passphrase))
+	if err != nil {
+		return err
+	}
+
+	c.textInputs[passIn].SetValue("")
+	c.keystore = store
+	// a round being typed in is kept, a malformed size is reported on save
+	_ = c.storeRound()
+	c.loadKeys()
+	c.showRound()
+
+	return nil
+}
+
+func (c *Config) loadKeys() {
+	if c.keystore == nil {
+		return
+	}
+
+	if key, ok := c.keystore.Get(c.keyName()); ok {
+		c.textInputs[keyIn].SetValue(key)
+	}
+
+	if key, ok := c.keystore.Get(keystore.SecondName(c.keyName())); ok {
+		c.textInputs[key2In].SetValue(key)
+	}
+
+	for i := range c.rounds {
+		if key, ok := c.keystore.Get(keystore.RoundName(c.keyName(), i+2)); ok {
+			c.rounds[i].Key = key
+		}
+	}
+}
+
+// storeRound copies the round inputs into the extra round being edited,
+// which is appended when it's a new one.
+func (c *Config) storeRound() error {
+	key, size := c.textInputs[roundIn].Value(), c.textInputs[roundSizeIn].Value()
+
+	r := model.Round{Key: key}
+	if size != "" {
+		if _, err := fmt.Sscanf(size, "%dx%d", &r.Height, &r.Width); err != nil {
+			return fmt.Errorf("round size must look like 6x6")
+		}
+	}
+
+	switch {
+	case c.roundIdx < len(c.rounds):
+		c.rounds[c.roundIdx] = r
+	case key != "":
+		c.rounds = append(c.rounds, r)
+	}
+
+	return nil
+}
+
+func (c *Config) showRound() {
+	var r model.Round
+	if c.roundIdx < len(c.rounds) {
+		r = c.rounds[c.roundIdx]
+	}
+
+	c.textInputs[roundIn].SetValue(r.Key)
+	c.textInputs[roundSizeIn].SetValue("")
+	if r.Height != 0 || r.Width != 0 {
+		c.textInputs[roundSizeIn].SetValue(fmt.Sprintf("%dx%d", r.Height, r.Width))
+	}
+}
+
+func (c *Config) nextRound() error {
+	if err := c.storeRound(); err != nil {
+		return err
+	}
+
+	c.roundIdx = (c.roundIdx + 1) % (len(c.rounds) + 1)
+	c.showRound()
+
+	return nil
+}
+
+func (c *Config) roundStatus() string {
+	if c.roundIdx == len(c.rounds) {
+		return fmt.Sprintf("%d (new)", c.roundIdx+2)
+	}
+
+	return fmt.Sprintf("%d of %d", c.roundIdx+2, len(c.rounds)+1)
+}
+
+func nextFill(fill model.Fill) model.Fill {
+	for i, f := range model.Fills {
+		if f == fill {
+			return model.Fills[(i+1)%len(model.Fills)]
+		}
+	}
+
+	return model.Fills[0]
+}
+
+// nextKDF switches the key derivation off and through the algorithms with
+// their default costs, keeping the salt so that switching back restores the
+// matrices.
+func (c *Config) nextKDF() error {
+	var alg model.KDFAlgorithm
+	if c.kdf != nil {
+		alg = c.kdf.Algorithm
+	}
+
+	next := model.KDFAlgorithms[0]
+	for i, a := range model.KDFAlgorithms {
+		if a == alg {
+			if i+1 == len(model.KDFAlgorithms) {
+				c.salt, c.kdf = c.kdf.Salt, nil
+				return nil
+			}
+			next = model.KDFAlgorithms[i+1]
+		}
+	}
+
+	var salt []byte
+	if c.kdf != nil {
+		salt = c.kdf.Salt
+	} else if c.salt != nil {
+		salt = c.salt
+	} else {
+		var err error
+		if salt, err = keymatrix.NewSalt(); err != nil {
+			return err
+		}
+	}
+
+	c.kdf = &model.KDF{Algorithm: next, Salt: salt}
+	return nil
+}
+
+func (c *Config) kdfStatus() string {
+	if c.kdf == nil {
+		return "keyword"
+	}
+
+	return fmt.Sprintf("passphrase (%s)", c.kdf.Algorithm)
+}
+
+func nextAlgorithm(algorithm model.Algorithm) model.Algorithm {
+	for i, a := range algorithms {
+		if a == algorithm {
+			return algorithms[(i+1)%len(algorithms)]
+		}
+	}
+
+	return algorithms[0]
+}
+
+func (c *Config) nextPreset() configfile.Preset {
+	presets := configfile.Presets()
+	c.presetIdx = (c.presetIdx + 1) % len(presets)
+	preset := presets[c.presetIdx]
+
+	c.textInputs[abcIn].SetValue(preset.Chars)
+	c.textInputs[heightIn].SetValue(strconv.Itoa(preset.Height))
+	c.textInputs[widthIn].SetValue(strconv.Itoa(preset.Width))
+	c.textInputs[depthIn].SetValue("")
+	if preset.Depth > 0 {
+		c.textInputs[depthIn].SetValue(strconv.Itoa(preset.Depth))
+	}
+	switch {
+	case preset.Algorithm != "":
+		c.algorithm = preset.Algorithm
+	case c.algorithm == model.Cube:
+		c.algorithm = model.Playfair
+	}
+	c.textInputs[sepIn].SetValue(string(preset.Separator))
+	c.textInputs[padIn].SetValue("")
+	c.textInputs[altIn].SetValue("")
+	c.merges = preset.Config().Merges
+
+	return preset
+}
+
+func (c *Config) keyName() string {
+	if profile := c.textInputs[profIn].Value(); profile != "" {
+		return profile
+	}
+
+	return keystore.DefaultName
+}
+
+func (c *Config) switchProfile(next bool) error {
+	names, err := c.profiles.List()
+	if err != nil {
+		return err
+	}
+
+	names = append([]string{""}, names...)
+	idx := findIdx(names, c.textInputs[profIn].Value())
+	if next {
+		idx = (idx + 1) % len(names)
+	} else {
+		idx = (idx - 1 + len(names)) % len(names)
+	}
+
+	c.textInputs[profIn].SetValue(names[idx])
+
+	return c.loadConfig()
+}
+
+func findIdx(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+
+	return 0
+}
+
 func (c *Config) View() string {
-	return fmt.Sprintf(`Key:
+	return fmt.Sprintf(`Algorithm:     %s
+Arrangement:   %s
+Key:
 %s %d
 %s
+Second key:
+%s %s
+Extra round:   %s
+%s
+Round size:
+%s
+Keystore passphrase:
+%s %s
 Separator character:
 %s
 %s
+Padding character:
+%s
+Alternate filler:
+%s
 
 Alphabet:
 %s %d
 %s
 Matrix height: %s %s
 Matrix width:  %s %s
+Cube depth:    %s %s
+Row shift:     %s %s
+Column shift:  %s %s
+Rectangle:     same %s corner
+Matrix fill:   %s
+Key from:      %s
+Merges:        %s
+Normalize:     case %s, diacritics %s, drop unknown %s
+Profile:
+%s %s
+Pass-through:  %s
+Strict filler: %s
 
                 (ctrl+s - save changes)
                (ctrl+z - restore settings)    
+               (ctrl+o - unlock keystore)
+    (ctrl+y / ctrl+r - next algorithm / arrangement)
+  (ctrl+n - next extra round, clear its key to drop it)
+   (ctrl+q / ctrl+j - toggle rectangle corner / next fill)
+      (alt+r - random key for the selected key field)
+   (alt+k - derive matrices from passphrases by a KDF)
+    (pgup / pgdown - previous / next profile)
+           (ctrl+f - set default profile)
+           (ctrl+e - apply next preset)
+             (ctrl+p - toggle pass-through)
+         (ctrl+t - toggle strict filler removal)
+   (ctrl+l / ctrl+g / ctrl+x - toggle case folding /
+      diacritics stripping / dropping unknown chars)
 %s`,
+		c.algorithm, c.arrangementStatus(),
 		c.textInputs[keyIn].View(), c.textInputs[keyIn].Position(), errorToText(textFieldValidator(c.textInputs[keyIn].Value(), "Key")),
+		c.textInputs[key2In].View(), c.secondKeyStatus(),
+		c.roundStatus(),
+		c.textInputs[roundIn].View(),
+		c.textInputs[roundSizeIn].View(),
+		c.textInputs[passIn].View(), keystoreStatus(c.keystore),
 		c.textInputs[sepIn].View(), errorToText(textFieldValidator(c.textInputs[sepIn].Value(), "Separator character")),
+		c.textInputs[padIn].View(),
+		c.textInputs[altIn].View(),
 		c.textInputs[abcIn].View(), c.textInputs[abcIn].Position(), errorToText(textFieldValidator(c.textInputs[abcIn].Value(), "Alphabet")),
 		c.textInputs[heightIn].View(), errorToText(numFieldValidator(c.textInputs[heightIn].Value(), "Matrix height")),
 		c.textInputs[widthIn].View(), errorToText(numFieldValidator(c.textInputs[widthIn].Value(), "Matrix width")),
+		c.textInputs[depthIn].View(), c.depthStatus(),
+		c.textInputs[rowShiftIn].View(), errorToText(shiftFieldValidator(c.textInputs[rowShiftIn].Value(), "Row shift")),
+		c.textInputs[colShiftIn].View(), errorToText(shiftFieldValidator(c.textInputs[colShiftIn].Value(), "Column shift")),
+		c.rectangle,
+		c.fill,
+		c.kdfStatus(),
+		mergesStatus(c.merges),
+		onOff(c.foldCase), onOff(c.stripDiacr), onOff(c.dropUnknown),
+		c.textInputs[profIn].View(), c.profileStatus(),
+		onOff(c.passThrough),
+		onOff(c.strict),
 		c.saveRes,
 	)
 }
 
+func (c *Config) arrangementStatus() string {
+	if c.algorithm != model.TwoSquare {
+		return "(two-square only)"
+	}
+
+	return string(c.arrangement)
+}
+
+func (c *Config) depthStatus() string {
+	if c.algorithm != model.Cube {
+		return "(cube only)"
+	}
+
+	return errorToText(numFieldValidator(c.textInputs[depthIn].Value(), "Cube depth"))
+}
+
+func (c *Config) secondKeyStatus() string {
+	if !c.algorithm.TwoKeys() {
+		return "(unused)"
+	}
+
+	return errorToText(textFieldValidator(c.textInputs[key2In].Value(), "Second key"))
+}
+
+func (c *Config) profileStatus() string {
+	profile := c.textInputs[profIn].Value()
+	switch {
+	case profile == "":
+		return "(" + filepath.Base(c.confFile) + ")"
+	case !c.profiles.Exists(profile):
+		return "(new, saved on ctrl+s)"
+	}
+
+	return ""
+}
+
+func mergesStatus(merges map[rune]rune) string {
+	if len(merges) == 0 {
+		return "none"
+	}
+
+	return configfile.FormatMerges(merges)
+}
+
+func keystoreStatus(store *keystore.Store) string {
+	if store == nil {
+		return "(locked)"
+	}
+
+	return "(unlocked)"
+}
+
+func runeToText(r *rune) string {
+	if r == nil {
+		return ""
+	}
+
+	return string([]rune{*r})
+}
+
+func textToRune(s string) *rune {
+	if s == "" {
+		return nil
+	}
+
+	return &[]rune(s)[0]
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+
+	return "off"
+}
+
 func errorToText(err error) string {
 	if err == nil {
 		return ""
@@ -287,6 +1030,32 @@ func textFieldValidator(s, field string) error {
 	return nil
 }
 
+// shiftFieldValidator accepts an empty field for the default shift.
+func shiftFieldValidator(s, _ string) error {
+	if s == "" {
+		return nil
+	}
+
+	num, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("value must be digital")
+	}
+
+	if num == 0 {
+		return fmt.Errorf("value must not be zero")
+	}
+
+	return nil
+}
+
+func shiftToText(shift int) string {
+	if shift == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(shift)
+}
+
 func numFieldValidator(s, _ string) error {
 	num, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
@@ -0,0 +1,270 @@
+package tab
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/internal/crib"
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// cells tried per offset, keeps a search over every offset short
+	cribMaxNodes   = 200000
+	cribMaxOffsets = 30
+)
+
+var unknownStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+func NewCrib(engine *playfair.Engine) *Crib {
+	ci := textinput.New()
+	ci.Placeholder = "known plaintext"
+	ci.Prompt = "> "
+	ci.CharLimit = 100
+	ci.Width = 50
+	ci.Cursor.Style = cursorStyle
+	ci.PromptStyle = focusedStyle
+	ci.TextStyle = focusedStyle
+	ci.Focus()
+
+	oi := textinput.New()
+	oi.Placeholder = "empty to try every offset"
+	oi.Prompt = "> "
+	oi.CharLimit = 5
+	oi.Width = 30
+	oi.Cursor.Style = cursorStyle
+	oi.PromptStyle = focusedStyle
+	oi.TextStyle = focusedStyle
+
+	ti := textarea.New()
+	ti.Placeholder = ""
+	ti.SetHeight(6)
+	ti.SetWidth(50)
+	ti.CharLimit = 2000
+
+	return &Crib{
+		engine: engine,
+
+		ci: ci,
+		oi: oi,
+		ti: ti,
+	}
+}
+
+var _ Tab = &Crib{}
+
+type Crib struct {
+	engine *playfair.Engine
+
+	focus     int
+	ci        textinput.Model
+	oi        textinput.Model
+	ti        textarea.Model
+	status    string
+	searching bool
+	matrices  []crib.Matrix
+	shown     int
+	err       error
+}
+
+// CribResultMsg carries the outcome of a crib search back to the tab that
+// started it.
+type CribResultMsg struct {
+	owner     *Crib
+	status    string
+	searching bool
+	matrices  []crib.Matrix
+	err       error
+}
+
+func (c *Crib) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case CribResultMsg:
+		if msg.owner != c {
+			break
+		}
+
+		c.searching = false
+		c.status, c.matrices, c.err = msg.status, msg.matrices, msg.err
+	case tea.KeyMsg:
+		switch keypress := msg.String(); keypress {
+		case "ctrl+v":
+			if buff, err := clipboard.ReadAll(); err == nil {
+				c.ti.SetValue(buff)
+			}
+		case "ctrl+d":
+			c.ti.SetValue("")
+		case "ctrl+g":
+			if c.searching {
+				break
+			}
+
+			c.status, c.matrices, c.shown = "", nil, 0
+			search, err := c.solve()
+			if c.err = err; err != nil {
+				break
+			}
+
+			c.searching, c.status = true, "searching..."
+			return func() tea.Msg {
+				res := search()
+				res.owner = c
+				return res
+			}
+		case "pgup":
+			c.shown = max(c.shown-1, 0)
+		case "pgdown":
+			c.shown = min(c.shown+1, max(len(c.matrices)-1, 0))
+		case "up", "down":
+			if keypress == "up" {
+				c.focus = (c.focus + 2) % 3
+			} else {
+				c.focus = (c.focus + 1) % 3
+			}
+
+			c.ci.Blur()
+			c.oi.Blur()
+			c.ti.Blur()
+			switch c.focus {
+			case 0:
+				c.ci.Focus()
+			case 1:
+				c.oi.Focus()
+			default:
+				c.ti.Focus()
+			}
+		default:
+			switch c.focus {
+			case 0:
+				c.ci, _ = c.ci.Update(msg)
+			case 1:
+				c.oi, _ = c.oi.Update(msg)
+			default:
+				c.ti, _ = c.ti.Update(msg)
+			}
+		}
+	}
+
+	return nil
+}
+
+// solve checks the fields and returns the search, which runs outside the
+// update loop.
+func (c *Crib) solve() (func() CribResultMsg, error) {
+	cfg := c.engine.Config()
+	if cfg.Algorithm != model.Playfair || len(cfg.Rounds) > 0 {
+		return nil, errors.New("the crib solver supports single round playfair only")
+	}
+
+	cipherText, cribChars := c.engine.GridText(c.ti.Value()), c.engine.GridText(c.ci.Value())
+	if err := textFieldValidator(string(cribChars), "Crib"); err != nil {
+		return nil, err
+	}
+
+	opts := crib.Options{
+		Chars:    cfg.Chars,
+		Height:   cfg.Height,
+		Width:    cfg.Width,
+		Rules:    cfg.Rules,
+		MaxNodes: cribMaxNodes,
+	}
+	fillers := c.engine.Fillers()
+
+	if c.oi.Value() != "" {
+		offset, err := strconv.Atoi(c.oi.Value())
+		if err != nil {
+			return nil, fmt.Errorf("value must be digital")
+		}
+
+		digraphs, err := crib.Pairs(cribChars, cipherText, offset, fillers)
+		if err != nil {
+			return nil, err
+		}
+
+		return func() CribResultMsg {
+			res, err := crib.Solve(context.Background(), digraphs, opts)
+			if err != nil {
+				return CribResultMsg{err: err}
+			}
+
+			msg := CribResultMsg{matrices: res.Matrices}
+			switch {
+			case len(res.Matrices) == 0 && res.Complete:
+				msg.status = "no consistent matrix"
+			case res.Complete:
+				msg.status = fmt.Sprintf("%d consistent matrices", len(res.Matrices))
+			default:
+				msg.status = fmt.Sprintf("%d consistent matrices, search stopped before the end", len(res.Matrices))
+			}
+
+			return msg
+		}, nil
+	}
+
+	opts.MaxMatrices = 1
+	return func() CribResultMsg {
+		consistent := make([]string, 0)
+		for offset := range cipherText {
+			digraphs, err := crib.Pairs(cribChars, cipherText, offset, fillers)
+			if err != nil || len(digraphs) == 0 {
+				continue
+			}
+
+			if res, err := crib.Solve(context.Background(), digraphs, opts); err == nil && (len(res.Matrices) > 0 || !res.Complete) {
+				consistent = append(consistent, strconv.Itoa(offset))
+			}
+		}
+
+		switch {
+		case len(consistent) == 0:
+			return CribResultMsg{status: "the crib fits no offset"}
+		case len(consistent) > cribMaxOffsets:
+			return CribResultMsg{status: fmt.Sprintf("%d possible offsets: %s, ...", len(consistent), strings.Join(consistent[:cribMaxOffsets], ", "))}
+		}
+
+		return CribResultMsg{status: "possible offsets: " + strings.Join(consistent, ", ")}
+	}, nil
+}
+
+func (c *Crib) View() string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf(`Crib:
+%s
+Offset in ciphertext:
+%s
+Ciphertext:
+%s
+`,
+		c.ci.View(),
+		c.oi.View(),
+		c.ti.View(),
+	))
+
+	switch {
+	case c.err != nil:
+		sb.WriteString(fmt.Sprintf("* %s\n", c.err.Error()))
+	case c.status != "":
+		sb.WriteString(lipgloss.NewStyle().Width(50).Render(c.status))
+		sb.WriteString("\n")
+	}
+
+	if len(c.matrices) > 0 {
+		sb.WriteString(fmt.Sprintf("\nMatrix %d of %d:\n%s\n", c.shown+1, len(c.matrices), c.matrices[c.shown].Format(unknownStyle.Render("·"))))
+	}
+
+	sb.WriteString(`
+       (ctrl+g - find matrices or offsets)
+    (pgup / pgdown - previous / next matrix)
+    (ctrl+v / ctrl+d - paste / clear ciphertext)`)
+
+	return sb.String()
+}
@@ -0,0 +1,48 @@
+package tab
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var layerStyle = lipgloss.NewStyle().PaddingRight(3)
+
+func isCube(engine *playfair.Engine) bool {
+	return engine.Config().Algorithm == model.Cube
+}
+
+// cubeView renders the cube layers side by side, the top one first.
+func cubeView(engine *playfair.Engine) string {
+	layers := engine.Grids()
+	blocks := make([]string, len(layers))
+	for l, layer := range layers {
+		rows := make([]string, 0, len(layer)+1)
+		rows = append(rows, fmt.Sprintf("layer %d", l+1))
+		for _, row := range layer {
+			cells := make([]string, len(row))
+			for j, char := range row {
+				cells[j] = string(char)
+				if char == ' ' {
+					cells[j] = "␣"
+				}
+			}
+			rows = append(rows, strings.Join(cells, " "))
+		}
+
+		blocks[l] = layerStyle.Render(strings.Join(rows, "\n"))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, blocks...)
+}
+
+func cubeHelp(engine *playfair.Engine) string {
+	if !isCube(engine) {
+		return ""
+	}
+
+	return "\n          (ctrl+l - show / hide cube layers)"
+}
@@ -4,14 +4,16 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/akaspb/playfair-cipher/internal/decipher"
+	"github.com/akaspb/playfair-cipher/internal/ngram"
+	"github.com/akaspb/playfair-cipher/internal/validate"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func NewDecipher(decipherService *decipher.Decipher, separator *rune) *Decipher {
+func NewDecipher(engine *playfair.Engine) *Decipher {
 	fi := textinput.New()
 	fi.Placeholder = "file name with extension"
 	fi.Prompt = "> "
@@ -35,8 +37,7 @@ func NewDecipher(decipherService *decipher.Decipher, separator *rune) *Decipher
 	ti.CharLimit = 500
 
 	return &Decipher{
-		decipherService: decipherService,
-		separator:       separator,
+		engine: engine,
 
 		fi: fi,
 		ti: ti,
@@ -47,17 +48,23 @@ func NewDecipher(decipherService *decipher.Decipher, separator *rune) *Decipher
 var _ Tab = &Decipher{}
 
 type Decipher struct {
-	decipherService *decipher.Decipher
-	separator       *rune
+	engine *playfair.Engine
 
 	fileIsSaved bool
+	showCube    bool
+	showCheck   bool
+	lang        *ngram.Model
+	report      validate.Report
 	fi          textinput.Model
 	ti          textarea.Model
 	to          textarea.Model
 	err         error
 }
 
-func (d *Decipher) Update(msg tea.Msg) {
+// checkProblems is the number of problems listed by the ciphertext check.
+const checkProblems = 5
+
+func (d *Decipher) Update(msg tea.Msg) tea.Cmd {
 	d.err = nil
 	d.fileIsSaved = false
 
@@ -81,6 +88,16 @@ func (d *Decipher) Update(msg tea.Msg) {
 			saveText = true
 		case "ctrl+d":
 			d.ti.SetValue("")
+		case "ctrl+l":
+			d.showCube = !d.showCube && isCube(d.engine)
+		case "ctrl+y":
+			d.showCheck = !d.showCheck
+			if d.showCheck && d.lang == nil {
+				cfg := d.engine.Config()
+				// loaded on first use, alphabets without n-grams are
+				// checked by structure only
+				d.lang, _ = ngram.Default(cfg.Chars, cfg.Merges)
+			}
 		case "up":
 			d.fi.Focus()
 			d.ti.Blur()
@@ -113,10 +130,14 @@ func (d *Decipher) Update(msg tea.Msg) {
 		}
 	}
 
-	deciphered, err := d.decipherService.Decode(d.ti.Value(), *d.separator)
+	if d.showCheck {
+		d.report = validate.Check(d.engine, d.ti.Value(), d.lang)
+	}
+
+	deciphered, err := d.engine.Decrypt(d.ti.Value())
 	if err != nil {
 		d.err = err
-		return
+		return nil
 	}
 
 	d.to.SetValue(deciphered)
@@ -133,6 +154,8 @@ func (d *Decipher) Update(msg tea.Msg) {
 			d.fileIsSaved = true
 		}
 	}
+
+	return nil
 }
 
 func (d *Decipher) View() string {
@@ -142,6 +165,14 @@ func (d *Decipher) View() string {
 		sb.WriteString("* deciphered text saved to file")
 	}
 	sb.WriteString("\n")
+	if d.showCube {
+		sb.WriteString(cubeView(d.engine))
+		sb.WriteString("\n")
+	}
+
+	if d.showCheck {
+		sb.WriteString(checkView(d.report))
+	}
 
 	if d.err != nil {
 		sb.WriteString(fmt.Sprintf(`Ciphertext:
@@ -159,11 +190,34 @@ Deciphered text:
 %s
      (ctrl+v / ctrl+r - load from clipboard / file)
       (ctrl+s / ctrl+w - save to clipboard / file)
-			 (ctrl+d - clear ciphertext)`,
+			 (ctrl+d - clear ciphertext)
+		   (ctrl+y - check ciphertext)%s`,
 			d.ti.View(),
 			d.to.View(),
+			cubeHelp(d.engine),
 		))
 	}
 
 	return sb.String()
 }
+
+func checkView(r validate.Report) string {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("Check: %s, %s", r.Verdict, r.Reason))
+	if r.Fit != nil {
+		sb.WriteString(fmt.Sprintf(" (fit %.2f)", *r.Fit))
+	}
+	sb.WriteString("\n")
+
+	for _, p := range r.Problems[:min(checkProblems, len(r.Problems))] {
+		sb.WriteString(fmt.Sprintf("  %d: %s\n", p.Offset, p.Message))
+	}
+	if n := len(r.Problems) - checkProblems; n > 0 {
+		sb.WriteString(fmt.Sprintf("  and %d more\n", n))
+	}
+	if n := len(r.Ambiguous); n > 0 {
+		sb.WriteString(fmt.Sprintf("  %d separators may be fillers or text\n", n))
+	}
+
+	return sb.String()
+}
@@ -4,5 +4,5 @@ import tea "github.com/charmbracelet/bubbletea"
 
 type Tab interface {
 	View() string
-	Update(tea.Msg)
+	Update(tea.Msg) tea.Cmd
 }
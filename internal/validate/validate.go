@@ -0,0 +1,116 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/akaspb/playfair-cipher/internal/ngram"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+type Verdict string
+
+const (
+	Plausible   Verdict = "plausible"
+	Doubtful    Verdict = "doubtful"
+	Implausible Verdict = "implausible"
+)
+
+const (
+	// n-grams needed before the language fit is trusted
+	minGrams = 20
+
+	plausibleFit   = 0.6
+	implausibleFit = 0.3
+)
+
+type Report struct {
+	Problems []playfair.Problem `json:"problems"`
+	Verdict  Verdict            `json:"verdict"`
+	Reason   string             `json:"reason"`
+	// Fit places the plaintext between random chars (0) and the language (1),
+	// it is left out when the text could not be judged by language.
+	Fit *float64 `json:"fit,omitempty"`
+	// Ambiguous lists the plaintext offsets of separators that may be fillers
+	// or part of the text, found only when the engine has strict fillers.
+	Ambiguous []int `json:"ambiguous,omitempty"`
+}
+
+// Check validates cipherText against engine and judges whether it came from
+// this matrix. A problem other than a stray separator makes it implausible;
+// otherwise the decryption is scored with lang, which may be nil when there
+// is no model for the alphabet. The plaintext is scored without strict
+// fillers, ambiguous fillers are reported apart from the verdict.
+func Check(engine *playfair.Engine, cipherText string, lang *ngram.Model) Report {
+	r := Report{Problems: engine.Validate(cipherText)}
+
+	var broken, stray int
+	for _, p := range r.Problems {
+		if p.Kind == playfair.StraySeparator {
+			stray++
+		} else {
+			broken++
+		}
+	}
+
+	if broken > 0 {
+		r.Verdict, r.Reason = Implausible, fmt.Sprintf("%d problems the cipher can't produce", broken)
+		return r
+	}
+
+	found := "no problems"
+	if stray > 0 {
+		found = fmt.Sprintf("%d stray separators", stray)
+	}
+
+	plainText, err := engine.Decrypt(cipherText)
+	var ambiguous *playfair.AmbiguousFillersError
+	if errors.As(err, &ambiguous) {
+		r.Ambiguous = ambiguous.Offsets
+		plainText, err = lenientDecrypt(engine, cipherText)
+	}
+
+	switch {
+	case err != nil:
+		r.Verdict, r.Reason = Doubtful, err.Error()
+		return r
+	case lang == nil && stray > 0:
+		r.Verdict, r.Reason = Doubtful, found+", no language model to judge the plaintext"
+		return r
+	case lang == nil:
+		r.Verdict, r.Reason = Plausible, found+", no language model to judge the plaintext"
+		return r
+	}
+
+	fit, grams := lang.Fit(plainText)
+	if grams < minGrams {
+		r.Verdict, r.Reason = Doubtful, found+", too short to judge the plaintext"
+		return r
+	}
+
+	r.Fit = &fit
+	switch {
+	case fit >= plausibleFit:
+		r.Verdict, r.Reason = Plausible, found+", the plaintext reads like the language"
+	case fit < implausibleFit:
+		r.Verdict, r.Reason = Implausible, found+", the plaintext looks random"
+	default:
+		r.Verdict, r.Reason = Doubtful, found+", the plaintext only partly reads like the language"
+	}
+
+	return r
+}
+
+// lenientDecrypt deciphers cipherText with the matrices of engine, guessing
+// the fillers.
+func lenientDecrypt(engine *playfair.Engine, cipherText string) (string, error) {
+	cfg := engine.Config()
+	cfg.StrictFillers = false
+
+	lenient, err := playfair.New(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	return lenient.Decrypt(cipherText)
+}
@@ -0,0 +1,87 @@
+package validate
+
+import (
+	"testing"
+
+	"github.com/akaspb/playfair-cipher/internal/ngram"
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+const (
+	latin25 = "abcdefghiklmnopqrstuvwxyz"
+
+	english = "itisatruthuniversallyacknowledgedthatasinglemaninpossession" +
+		"ofagoodfortunemustbeinwantofawife"
+)
+
+func newEngine(t *testing.T, key string, strict bool) *playfair.Engine {
+	t.Helper()
+	separator := 'x'
+	engine, err := playfair.New(playfair.Config{
+		Height: 5, Width: 5, Chars: []rune(latin25), Key: key,
+		Separator: &separator, Merges: map[rune]rune{'j': 'i'}, StrictFillers: strict,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return engine
+}
+
+func TestCheck(t *testing.T) {
+	lang, err := ngram.Default([]rune(latin25), map[rune]rune{'j': 'i'})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engine := newEngine(t, "monarchy", false)
+	cipherText, err := engine.Encrypt(english)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherText, err := newEngine(t, "keyword", false).Encrypt(english)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		cipherText string
+		lang       *ngram.Model
+		want       Verdict
+	}{
+		{name: "right matrix", cipherText: cipherText, lang: lang, want: Plausible},
+		{name: "no language model", cipherText: cipherText, want: Plausible},
+		{name: "too short", cipherText: cipherText[:10], lang: lang, want: Doubtful},
+		{name: "odd length", cipherText: cipherText[:11], lang: lang, want: Implausible},
+		{name: "doubled digraph", cipherText: "aa" + cipherText, lang: lang, want: Implausible},
+		{name: "foreign char", cipherText: "j" + cipherText[1:], lang: lang, want: Implausible},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Check(engine, tt.cipherText, tt.lang)
+			if r.Verdict != tt.want {
+				t.Errorf("Check() verdict = %s (%s), want %s", r.Verdict, r.Reason, tt.want)
+			}
+		})
+	}
+
+	if r := Check(engine, otherText, lang); r.Verdict == Plausible {
+		t.Errorf("Check() of another matrix's ciphertext is plausible: %s", r.Reason)
+	}
+}
+
+func TestCheckReportsAmbiguousFillers(t *testing.T) {
+	strict := newEngine(t, "monarchy", true)
+	cipherText, err := strict.Encrypt("balloon")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := Check(strict, cipherText, nil)
+	if r.Verdict != Plausible || len(r.Ambiguous) != 1 || r.Ambiguous[0] != 3 {
+		t.Errorf("Check() = %s (%s), ambiguous %v, want plausible with ambiguous [3]", r.Verdict, r.Reason, r.Ambiguous)
+	}
+}
@@ -0,0 +1,199 @@
+// Package analysis computes the text statistics used to tell Playfair
+// ciphertext from plaintext and from other ciphers.
+package analysis
+
+import (
+	"slices"
+	"unicode"
+
+	"github.com/akaspb/playfair-cipher/internal/ngram"
+)
+
+// Expected returns the rune probabilities of the built-in n-grams for the
+// alphabet chars with merges, for Options.Expected. Alphabets without
+// n-grams are analyzed without the language profile, it returns nil for them.
+func Expected(chars []rune, merges map[rune]rune) map[rune]float64 {
+	m, err := ngram.Default(chars, merges)
+	if err != nil {
+		return nil
+	}
+
+	return m.Frequencies()
+}
+
+type Options struct {
+	// Alphabet restricts the text to these runes, looking up the other case
+	// of runes missing from it. Empty keeps every letter and digit.
+	Alphabet []rune
+	// Overlapping counts every digraph instead of the aligned pairs a
+	// Playfair cipher works on.
+	Overlapping bool
+	// Expected maps runes to their probability in the language, it enables
+	// the chi-square test.
+	Expected map[rune]float64
+}
+
+// Count is the number of times Text occurs.
+type Count struct {
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+// ReversedPair is a digraph seen together with its reversal. Playfair turns
+// AB and BA into reversed cipher pairs, so they are common in its output.
+type ReversedPair struct {
+	Pair         string `json:"pair"`
+	Reverse      string `json:"reverse"`
+	Count        int    `json:"count"`
+	ReverseCount int    `json:"reverse_count"`
+}
+
+type Report struct {
+	Length   int     `json:"length"`
+	Letters  []Count `json:"letters"`
+	Digraphs []Count `json:"digraphs"`
+	// DoubledDigraphs counts aligned pairs of the same rune, which Playfair
+	// ciphertext never has.
+	DoubledDigraphs    int            `json:"doubled_digraphs"`
+	IndexOfCoincidence float64        `json:"index_of_coincidence"`
+	ReversedPairs      []ReversedPair `json:"reversed_pairs"`
+	// LanguageIC and ChiSquare are only set with Options.Expected.
+	LanguageIC float64 `json:"language_ic,omitempty"`
+	ChiSquare  float64 `json:"chi_square,omitempty"`
+}
+
+// Analyze returns the statistics of text. Counts are sorted from the most
+// frequent, ties in text order.
+func Analyze(text []rune, opts Options) Report {
+	text = filter(text, opts.Alphabet)
+
+	r := Report{
+		Length:   len(text),
+		Letters:  make([]Count, 0),
+		Digraphs: make([]Count, 0),
+
+		ReversedPairs: make([]ReversedPair, 0),
+	}
+
+	letters := make(map[string]int)
+	for _, char := range text {
+		r.Letters = add(r.Letters, letters, string(char))
+	}
+
+	step := 2
+	if opts.Overlapping {
+		step = 1
+	}
+
+	digraphs := make(map[string]int)
+	for k := 0; k+1 < len(text); k += step {
+		r.Digraphs = add(r.Digraphs, digraphs, string(text[k:k+2]))
+		if k%2 == 0 && text[k] == text[k+1] {
+			r.DoubledDigraphs++
+		}
+	}
+
+	for _, d := range r.Digraphs {
+		pair := []rune(d.Text)
+		reverse := string([]rune{pair[1], pair[0]})
+		if pair[0] >= pair[1] {
+			continue
+		}
+
+		if idx, ok := digraphs[reverse]; ok {
+			r.ReversedPairs = append(r.ReversedPairs, ReversedPair{
+				Pair:         d.Text,
+				Reverse:      reverse,
+				Count:        d.Count,
+				ReverseCount: r.Digraphs[idx].Count,
+			})
+		}
+	}
+
+	sortCounts(r.Letters)
+	sortCounts(r.Digraphs)
+	slices.SortStableFunc(r.ReversedPairs, func(a, b ReversedPair) int {
+		return b.Count + b.ReverseCount - a.Count - a.ReverseCount
+	})
+
+	if n := float64(len(text)); n > 1 {
+		var sum float64
+		for _, c := range r.Letters {
+			sum += float64(c.Count * (c.Count - 1))
+		}
+		r.IndexOfCoincidence = sum / (n * (n - 1))
+	}
+
+	if len(opts.Expected) > 0 {
+		r.LanguageIC, r.ChiSquare = compare(r, opts.Expected)
+	}
+
+	return r
+}
+
+// add counts text, positions keeps where each text is in counts.
+func add(counts []Count, positions map[string]int, text string) []Count {
+	if idx, ok := positions[text]; ok {
+		counts[idx].Count++
+		return counts
+	}
+
+	positions[text] = len(counts)
+
+	return append(counts, Count{Text: text, Count: 1})
+}
+
+func sortCounts(counts []Count) {
+	slices.SortStableFunc(counts, func(a, b Count) int {
+		return b.Count - a.Count
+	})
+}
+
+func compare(r Report, expected map[rune]float64) (ic, chi float64) {
+	observed := make(map[rune]int, len(r.Letters))
+	for _, c := range r.Letters {
+		observed[[]rune(c.Text)[0]] = c.Count
+	}
+
+	var total float64
+	for _, p := range expected {
+		total += p
+		ic += p * p
+	}
+	if total > 0 {
+		ic /= total * total
+	}
+
+	for char, p := range expected {
+		if p <= 0 {
+			continue
+		}
+
+		exp := float64(r.Length) * p / total
+		diff := float64(observed[char]) - exp
+		chi += diff * diff / exp
+	}
+
+	return ic, chi
+}
+
+func filter(text []rune, alphabet []rune) []rune {
+	res := make([]rune, 0, len(text))
+	for _, char := range text {
+		if len(alphabet) == 0 {
+			if unicode.IsLetter(char) || unicode.IsDigit(char) {
+				res = append(res, char)
+			}
+			continue
+		}
+
+		for _, c := range []rune{char, unicode.ToLower(char), unicode.ToUpper(char)} {
+			if slices.Contains(alphabet, c) {
+				res = append(res, c)
+				break
+			}
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,558 @@
+// Package playfair exposes the Playfair cipher engine used by the TUI
+// so that other programs can encrypt and decrypt without it.
+package playfair
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/akaspb/playfair-cipher/internal/cipher"
+	"github.com/akaspb/playfair-cipher/internal/decipher"
+	"github.com/akaspb/playfair-cipher/internal/keymatrix"
+	"github.com/akaspb/playfair-cipher/internal/model"
+	"github.com/akaspb/playfair-cipher/internal/normalize"
+)
+
+type (
+	Config                = model.Config
+	Algorithm             = model.Algorithm
+	Arrangement           = model.Arrangement
+	Rules                 = model.Rules
+	Rectangle             = model.Rectangle
+	Fill                  = model.Fill
+	Round                 = model.Round
+	KDF                   = model.KDF
+	KDFAlgorithm          = model.KDFAlgorithm
+	Pos                   = model.Pos
+	Pos3                  = model.Pos3
+	Fillers               = model.Fillers
+	AmbiguousFillersError = decipher.AmbiguousFillersError
+	NormalizeReport       = normalize.Report
+)
+
+const (
+	Playfair   = model.Playfair
+	TwoSquare  = model.TwoSquare
+	FourSquare = model.FourSquare
+	Cube       = model.Cube
+
+	Horizontal = model.Horizontal
+	Vertical   = model.Vertical
+
+	SameRow    = model.SameRow
+	SameColumn = model.SameColumn
+
+	FillRows      = model.FillRows
+	FillColumns   = model.FillColumns
+	FillSpiralCW  = model.FillSpiralCW
+	FillSpiralCCW = model.FillSpiralCCW
+	FillSnake     = model.FillSnake
+	FillDiagonal  = model.FillDiagonal
+	FillColumnar  = model.FillColumnar
+
+	PBKDF2   = model.PBKDF2
+	Scrypt   = model.Scrypt
+	Argon2id = model.Argon2id
+)
+
+type encoder interface {
+	Code(text string, fillers model.Fillers) (string, error)
+	CodePassThrough(text string, fillers model.Fillers) (string, error)
+}
+
+type decoder interface {
+	Decode(cipherText string, fillers model.Fillers) (string, error)
+	DecodePassThrough(cipherText string, fillers model.Fillers) (string, error)
+}
+
+type round struct {
+	enc encoder
+	dec decoder
+}
+
+// Engine encrypts and decrypts text with keyed matrices built from a Config.
+type Engine struct {
+	cfg       Config
+	grids     [][][]rune
+	positions []map[rune]Pos
+	fillers   model.Fillers
+	rounds    []round
+
+	cube          [][][]rune
+	cubePositions map[rune]Pos3
+}
+
+// New builds the keyed matrices described by cfg and returns an Engine for
+// the selected Config.Algorithm, Playfair by default. Config.Rounds add
+// further Playfair passes with their own keys, Config.Rules adjust the
+// Playfair shifts and rectangle rule and must be zero for other algorithms.
+// With Config.KDF set the keys are passphrases that shuffle the whole
+// alphabet.
+func New(cfg Config) (*Engine, error) {
+	if cfg.Separator == nil {
+		return nil, errors.New("[separator] must be set")
+	}
+
+	if cfg.Algorithm == "" {
+		cfg.Algorithm = model.Playfair
+	}
+
+	var err error
+	if cfg.KDF != nil {
+		if cfg, err = deriveKeys(cfg); err != nil {
+			return nil, err
+		}
+		cfg.KDF = nil
+	}
+
+	keys, err := keysOf(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Engine{cfg: cfg}
+	if err := e.buildGrids(keys); err != nil {
+		return nil, err
+	}
+
+	if e.fillers, err = fillersOf(cfg, e.has); err != nil {
+		return nil, err
+	}
+
+	for from, to := range cfg.Merges {
+		if !e.has(to) {
+			return nil, fmt.Errorf("merge target '%c' not in grid", to)
+		}
+
+		if e.has(from) {
+			return nil, fmt.Errorf("merged char '%c' must not be in grid", from)
+		}
+	}
+
+	if err := e.setup(); err != nil {
+		return nil, err
+	}
+
+	if err := e.setupRounds(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// deriveKeys replaces the passphrases of cfg with the alphabet shuffles
+// derived from them by cfg.KDF.
+func deriveKeys(cfg Config) (Config, error) {
+	derive := func(key *string, field string) error {
+		if *key == "" {
+			return nil
+		}
+
+		shuffled, err := keymatrix.Derive(cfg.Chars, *key, *cfg.KDF)
+		if err != nil {
+			return fmt.Errorf("%s: %w", field, err)
+		}
+
+		*key = string(shuffled)
+		return nil
+	}
+
+	if err := derive(&cfg.Key, "key"); err != nil {
+		return Config{}, err
+	}
+
+	if err := derive(&cfg.SecondKey, "second key"); err != nil {
+		return Config{}, err
+	}
+
+	cfg.Rounds = append([]Round(nil), cfg.Rounds...)
+	for i := range cfg.Rounds {
+		if err := derive(&cfg.Rounds[i].Key, fmt.Sprintf("round %d", i+2)); err != nil {
+			return Config{}, err
+		}
+	}
+
+	return cfg, nil
+}
+
+func keysOf(cfg Config) ([]string, error) {
+	switch cfg.Algorithm {
+	case model.Playfair, model.Cube:
+		return []string{cfg.Key}, nil
+	case model.TwoSquare, model.FourSquare:
+		if cfg.SecondKey == "" {
+			return nil, errors.New("[second key] must be non-empty string")
+		}
+
+		return []string{cfg.Key, cfg.SecondKey}, nil
+	}
+
+	return nil, fmt.Errorf("unknown algorithm '%s'", cfg.Algorithm)
+}
+
+// buildGrids keeps the layers of a cube as its grids, so that Grids and
+// String show them as well.
+func (e *Engine) buildGrids(keys []string) error {
+	if e.cfg.Algorithm == model.Cube {
+		if e.cfg.Fill != "" && e.cfg.Fill != model.FillRows {
+			return fmt.Errorf("[fill] '%s' is not supported by cube", e.cfg.Fill)
+		}
+
+		cube, positions, err := keymatrix.CalculateCube(e.cfg.Chars, e.cfg.Height, e.cfg.Width, e.cfg.Depth, keys[0])
+		if err != nil {
+			return err
+		}
+
+		e.cube, e.cubePositions = cube, positions
+		e.grids = cube
+		e.positions = make([]map[rune]Pos, len(cube))
+		for l := range e.positions {
+			e.positions[l] = make(map[rune]Pos, e.cfg.Height*e.cfg.Width)
+		}
+
+		for char, pos := range positions {
+			e.positions[pos.L()][char] = pos.Pos()
+		}
+
+		return nil
+	}
+
+	for _, key := range keys {
+		grid, positions, err := keymatrix.CalculateFill(e.cfg.Chars, e.cfg.Height, e.cfg.Width, key, e.cfg.Fill)
+		if err != nil {
+			return err
+		}
+
+		e.grids = append(e.grids, grid)
+		e.positions = append(e.positions, positions)
+	}
+
+	return nil
+}
+
+func (e *Engine) has(char rune) bool {
+	for _, positions := range e.positions {
+		if _, ok := positions[char]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *Engine) setup() error {
+	if e.cfg.Algorithm != model.Playfair && e.cfg.Rules != (model.Rules{}) {
+		return fmt.Errorf("[rules] are not supported by %s", e.cfg.Algorithm)
+	}
+
+	switch e.cfg.Algorithm {
+	case model.TwoSquare:
+		arrangement := e.cfg.Arrangement
+		if arrangement == "" {
+			arrangement = model.Horizontal
+		}
+
+		enc, err := cipher.NewTwoSquare(&e.grids[0], &e.grids[1], &e.positions[0], &e.positions[1], arrangement)
+		if err != nil {
+			return err
+		}
+
+		dec, err := decipher.NewTwoSquare(&e.grids[0], &e.grids[1], &e.positions[0], &e.positions[1], arrangement)
+		if err != nil {
+			return err
+		}
+		dec.Strict = e.cfg.StrictFillers
+
+		e.rounds = append(e.rounds, round{enc, dec})
+	case model.FourSquare:
+		plain, positions, err := keymatrix.Plain(e.cfg.Chars, e.cfg.Height, e.cfg.Width)
+		if err != nil {
+			return err
+		}
+
+		e.grids = append(e.grids, plain)
+		e.positions = append(e.positions, positions)
+
+		enc, err := cipher.NewFourSquare(&e.grids[2], &e.positions[2], &e.grids[0], &e.grids[1])
+		if err != nil {
+			return err
+		}
+
+		dec, err := decipher.NewFourSquare(&e.grids[2], &e.positions[0], &e.positions[1])
+		if err != nil {
+			return err
+		}
+		dec.Strict = e.cfg.StrictFillers
+
+		e.rounds = append(e.rounds, round{enc, dec})
+	case model.Cube:
+		enc, err := cipher.NewCube(&e.cube, &e.cubePositions)
+		if err != nil {
+			return err
+		}
+
+		dec, err := decipher.NewCube(&e.cube, &e.cubePositions)
+		if err != nil {
+			return err
+		}
+		dec.Strict = e.cfg.StrictFillers
+
+		e.rounds = append(e.rounds, round{enc, dec})
+	default:
+		if err := checkRules(e.cfg.Rules, e.cfg.Height, e.cfg.Width); err != nil {
+			return err
+		}
+
+		enc, err := cipher.New(&e.grids[0], &e.positions[0])
+		if err != nil {
+			return err
+		}
+		enc.Rules = e.cfg.Rules
+
+		dec, err := decipher.New(&e.grids[0], &e.positions[0])
+		if err != nil {
+			return err
+		}
+		dec.Strict = e.cfg.StrictFillers
+		dec.Rules = e.cfg.Rules
+
+		e.rounds = append(e.rounds, round{enc, dec})
+	}
+
+	return nil
+}
+
+func (e *Engine) setupRounds() error {
+	if len(e.cfg.Rounds) > 0 && e.cfg.Algorithm != model.Playfair {
+		return fmt.Errorf("[rounds] are not supported by %s", e.cfg.Algorithm)
+	}
+
+	for i, r := range e.cfg.Rounds {
+		height, width := r.Height, r.Width
+		if height == 0 && width == 0 {
+			height, width = e.cfg.Height, e.cfg.Width
+		}
+
+		grid, positions, err := keymatrix.CalculateFill(e.cfg.Chars, height, width, r.Key, e.cfg.Fill)
+		if err != nil {
+			return fmt.Errorf("round %d: %w", i+2, err)
+		}
+
+		e.grids = append(e.grids, grid)
+		e.positions = append(e.positions, positions)
+
+		if err := checkRules(e.cfg.Rules, height, width); err != nil {
+			return fmt.Errorf("round %d: %w", i+2, err)
+		}
+
+		enc, err := cipher.New(&grid, &positions)
+		if err != nil {
+			return err
+		}
+		enc.Rules = e.cfg.Rules
+
+		dec, err := decipher.New(&grid, &positions)
+		if err != nil {
+			return err
+		}
+		dec.Rules = e.cfg.Rules
+
+		e.rounds = append(e.rounds, round{enc, dec})
+	}
+
+	return nil
+}
+
+func checkRules(rules model.Rules, height, width int) error {
+	switch rules.Rectangle {
+	case "", model.SameRow, model.SameColumn:
+	default:
+		return fmt.Errorf("unknown rectangle rule '%s'", rules.Rectangle)
+	}
+
+	rowShift, columnShift := rules.Shifts()
+	if rowShift%width == 0 {
+		return fmt.Errorf("[row shift] %d leaves chars in place in a matrix %d wide", rowShift, width)
+	}
+
+	if columnShift%height == 0 {
+		return fmt.Errorf("[column shift] %d leaves chars in place in a matrix %d high", columnShift, height)
+	}
+
+	return nil
+}
+
+// Encrypt returns the ciphertext of text after normalizing it. When
+// Config.PassThrough is set, runes missing from the matrix are kept unchanged
+// at their positions. Fillers are inserted by the first round only.
+func (e *Engine) Encrypt(text string) (res string, err error) {
+	res, _ = e.Normalize(text)
+
+	fillers := e.fillers
+	for _, r := range e.rounds {
+		if e.cfg.PassThrough {
+			res, err = r.enc.CodePassThrough(res, fillers)
+		} else {
+			res, err = r.enc.Code(res, fillers)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		fillers = model.Fillers{}
+	}
+
+	return res, nil
+}
+
+// Decrypt returns the plaintext of cipherText with inserted fillers removed.
+// When Config.StrictFillers is set, an *AmbiguousFillersError listing
+// possible filler offsets is returned instead of guessing.
+func (e *Engine) Decrypt(cipherText string) (res string, err error) {
+	res = cipherText
+	for i := len(e.rounds) - 1; i >= 0; i-- {
+		var fillers model.Fillers
+		if i == 0 {
+			fillers = e.fillers
+		}
+
+		if e.cfg.PassThrough {
+			res, err = e.rounds[i].dec.DecodePassThrough(res, fillers)
+		} else {
+			res, err = e.rounds[i].dec.Decode(res, fillers)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return res, nil
+}
+
+// Normalize applies case folding, diacritic stripping, Config.Merges and
+// removal of unknown runes as enabled in the Config, and reports every change.
+func (e *Engine) Normalize(text string) (string, NormalizeReport) {
+	opts := normalize.Options{
+		FoldCase:        e.cfg.FoldCase,
+		StripDiacritics: e.cfg.StripDiacritics,
+		Merges:          e.cfg.Merges,
+		DropUnknown:     e.cfg.DropUnknown,
+	}
+	if opts.IsZero() {
+		return text, NormalizeReport{}
+	}
+
+	return normalize.Text(text, e.has, opts)
+}
+
+// GridText normalizes text and drops the runes missing from the matrices,
+// leaving the runes a cipher works on.
+func (e *Engine) GridText(text string) []rune {
+	text, _ = e.Normalize(text)
+
+	res := make([]rune, 0, len(text))
+	for _, char := range text {
+		if e.has(char) {
+			res = append(res, char)
+		}
+	}
+
+	return res
+}
+
+func fillersOf(cfg Config, has func(rune) bool) (Fillers, error) {
+	fillers := Fillers{Double: *cfg.Separator, Pad: *cfg.Separator}
+	if cfg.PadFiller != nil {
+		fillers.Pad = *cfg.PadFiller
+	}
+
+	if cfg.AltFiller != nil {
+		fillers.Alt = *cfg.AltFiller
+	}
+
+	if !has(fillers.Double) {
+		return Fillers{}, fmt.Errorf("[separator] '%c' not in grid", fillers.Double)
+	}
+
+	if !has(fillers.Pad) {
+		return Fillers{}, fmt.Errorf("[padding filler] '%c' not in grid", fillers.Pad)
+	}
+
+	if fillers.Alt == 0 {
+		return fillers, nil
+	}
+
+	if !has(fillers.Alt) {
+		return Fillers{}, fmt.Errorf("[alternate filler] '%c' not in grid", fillers.Alt)
+	}
+
+	if fillers.Alt == fillers.Double || fillers.Alt == fillers.Pad {
+		return Fillers{}, errors.New("[alternate filler] must differ from separator and padding filler")
+	}
+
+	return fillers, nil
+}
+
+// Fillers returns the fillers inserted between doubled chars and as padding.
+func (e *Engine) Fillers() Fillers {
+	return e.fillers
+}
+
+// Config returns the configuration the Engine was built from. Keys given as
+// passphrases come back derived and without Config.KDF, so New(e.Config())
+// builds the same matrices.
+func (e *Engine) Config() Config {
+	return e.cfg
+}
+
+// Grid returns a copy of the first keyed matrix.
+func (e *Engine) Grid() [][]rune {
+	return copyGrid(e.grids[0])
+}
+
+// Grids returns copies of all keyed matrices in key order, followed by the
+// plain matrix for Four-Square or the matrices of further Config.Rounds.
+// For Cube these are its layers from top to bottom.
+func (e *Engine) Grids() [][][]rune {
+	grids := make([][][]rune, len(e.grids))
+	for i, grid := range e.grids {
+		grids[i] = copyGrid(grid)
+	}
+
+	return grids
+}
+
+func copyGrid(src [][]rune) [][]rune {
+	grid := make([][]rune, len(src))
+	for i, row := range src {
+		grid[i] = append([]rune(nil), row...)
+	}
+
+	return grid
+}
+
+// Position reports where char is located in the first keyed matrix.
+func (e *Engine) Position(char rune) (Pos, bool) {
+	pos, ok := e.positions[0][char]
+	return pos, ok
+}
+
+// CubePosition reports where char is located in the cube of the Cube algorithm.
+func (e *Engine) CubePosition(char rune) (Pos3, bool) {
+	pos, ok := e.cubePositions[char]
+	return pos, ok
+}
+
+func (e *Engine) String() string {
+	blocks := make([]string, len(e.grids))
+	for i, grid := range e.grids {
+		rows := make([]string, len(grid))
+		for j, row := range grid {
+			rows[j] = string(row)
+		}
+		blocks[i] = strings.Join(rows, "\n")
+	}
+
+	return strings.Join(blocks, "\n\n")
+}
@@ -0,0 +1,229 @@
+package playfair_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/akaspb/playfair-cipher/pkg/playfair"
+)
+
+// the 5x5 alphabet without j, which is merged into i
+const latin25 = "abcdefghiklmnopqrstuvwxyz"
+
+// the 5x5 alphabet without q, as in the four-square example
+const latinNoQ = "abcdefghijklmnoprstuvwxyz"
+
+func runePtr(r rune) *rune {
+	return &r
+}
+
+func TestVectors(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        playfair.Config
+		plainText  string
+		cipherText string
+	}{
+		{
+			name: "wikipedia playfair",
+			cfg: playfair.Config{
+				Height: 5, Width: 5, Chars: []rune(latin25), Key: "playfairexample",
+				Separator: runePtr('x'), Merges: map[rune]rune{'j': 'i'},
+			},
+			plainText:  "hidethegoldinthetreestump",
+			cipherText: "bmodzbxdnabekudmuixmmouvif",
+		},
+		{
+			name: "wikipedia four-square",
+			cfg: playfair.Config{
+				Algorithm: playfair.FourSquare, Height: 5, Width: 5, Chars: []rune(latinNoQ),
+				Key: "example", SecondKey: "keyword", Separator: runePtr('x'),
+			},
+			plainText:  "helpmeobiwankenobi",
+			cipherText: "fygmkyhobxmfkkkimd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := playfair.New(tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cipherText, err := engine.Encrypt(tt.plainText)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cipherText != tt.cipherText {
+				t.Errorf("Encrypt(%q) = %q, want %q", tt.plainText, cipherText, tt.cipherText)
+			}
+
+			plainText, err := engine.Decrypt(tt.cipherText)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if plainText != tt.plainText {
+				t.Errorf("Decrypt(%q) = %q, want %q", tt.cipherText, plainText, tt.plainText)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	base := playfair.Config{
+		Height: 5, Width: 5, Chars: []rune(latin25), Key: "monarchy", SecondKey: "keyword",
+		Separator: runePtr('x'), Merges: map[rune]rune{'j': 'i'},
+	}
+
+	texts := []string{"balloonsmeetinthehallway", "oddlength", "aa"}
+	// texts holding fillers need an alternate filler to come back unchanged
+	fillerTexts := append([]string{"xx", "taxi", "lengthx", "abcx", "abxq"}, texts...)
+
+	configs := map[string]func(cfg *playfair.Config){
+		"playfair": func(*playfair.Config) {},
+		"pass-through": func(cfg *playfair.Config) {
+			cfg.PassThrough = true
+		},
+		"two-square horizontal": func(cfg *playfair.Config) {
+			cfg.Algorithm, cfg.Arrangement = playfair.TwoSquare, playfair.Horizontal
+		},
+		"two-square vertical": func(cfg *playfair.Config) {
+			cfg.Algorithm, cfg.Arrangement = playfair.TwoSquare, playfair.Vertical
+		},
+		"four-square": func(cfg *playfair.Config) {
+			cfg.Algorithm = playfair.FourSquare
+		},
+		"playfair rounds": func(cfg *playfair.Config) {
+			cfg.Rounds = []playfair.Round{{Key: "keyword"}}
+		},
+		"playfair rules": func(cfg *playfair.Config) {
+			cfg.Rules = playfair.Rules{RowShift: 2, ColumnShift: -1, Rectangle: playfair.SameColumn}
+		},
+		"cube": func(cfg *playfair.Config) {
+			cfg.Algorithm = playfair.Cube
+			cfg.Height, cfg.Width, cfg.Depth = 3, 3, 3
+			cfg.Chars, cfg.Merges = []rune("abcdefghijklmnopqrstuvwxyz."), nil
+		},
+		"pad filler": func(cfg *playfair.Config) {
+			cfg.PadFiller = runePtr('z')
+		},
+		"alt filler": func(cfg *playfair.Config) {
+			cfg.AltFiller = runePtr('q')
+		},
+	}
+
+	for _, fill := range []playfair.Fill{
+		playfair.FillColumns, playfair.FillSpiralCW, playfair.FillSpiralCCW,
+		playfair.FillSnake, playfair.FillDiagonal, playfair.FillColumnar,
+	} {
+		configs["fill "+string(fill)] = func(cfg *playfair.Config) {
+			cfg.Fill = fill
+		}
+	}
+
+	for name, apply := range configs {
+		cfg := base
+		apply(&cfg)
+
+		engine, err := playfair.New(cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		cases := texts
+		if cfg.AltFiller != nil {
+			cases = fillerTexts
+		}
+
+		for _, text := range cases {
+			if cfg.PassThrough {
+				text = "hello, world! " + text
+			}
+
+			t.Run(name+"/"+text, func(t *testing.T) {
+				cipherText, err := engine.Encrypt(text)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				plainText, err := engine.Decrypt(cipherText)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if plainText != text {
+					t.Errorf("Decrypt(Encrypt(%q)) = %q via %q", text, plainText, cipherText)
+				}
+			})
+		}
+	}
+}
+
+func TestStrictFillers(t *testing.T) {
+	engine, err := playfair.New(playfair.Config{
+		Height: 5, Width: 5, Chars: []rune(latin25), Key: "monarchy",
+		Separator: runePtr('x'), Merges: map[rune]rune{'j': 'i'}, StrictFillers: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		text    string
+		offsets []int
+	}{
+		{text: "hide"},
+		{text: "balloon", offsets: []int{3}},
+		{text: "hid", offsets: []int{3}},
+		{text: "taxi"},
+		{text: "lxl", offsets: []int{1, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			cipherText, err := engine.Encrypt(tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = engine.Decrypt(cipherText)
+			var ambiguous *playfair.AmbiguousFillersError
+			switch {
+			case tt.offsets == nil && err != nil:
+				t.Fatalf("Decrypt(%q) error = %v", cipherText, err)
+			case tt.offsets != nil && !errors.As(err, &ambiguous):
+				t.Fatalf("Decrypt(%q) error = %v, want *AmbiguousFillersError", cipherText, err)
+			case tt.offsets != nil && fmt.Sprint(ambiguous.Offsets) != fmt.Sprint(tt.offsets):
+				t.Errorf("offsets = %v, want %v", ambiguous.Offsets, tt.offsets)
+			}
+		})
+	}
+}
+
+func TestConfigRebuildsEngine(t *testing.T) {
+	for _, alg := range []playfair.KDFAlgorithm{playfair.PBKDF2, playfair.Scrypt, playfair.Argon2id} {
+		t.Run(string(alg), func(t *testing.T) {
+			engine, err := playfair.New(playfair.Config{
+				Height: 5, Width: 5, Chars: []rune(latin25), Key: "correct horse battery staple",
+				Separator: runePtr('x'), Merges: map[rune]rune{'j': 'i'},
+				KDF: &playfair.KDF{Algorithm: alg, Salt: []byte("0123456789abcdef")},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rebuilt, err := playfair.New(engine.Config())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if rebuilt.String() != engine.String() {
+				t.Errorf("New(e.Config()) matrix =\n%s\nwant\n%s", rebuilt, engine)
+			}
+		})
+	}
+}
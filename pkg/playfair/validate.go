@@ -0,0 +1,149 @@
+package playfair
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/akaspb/playfair-cipher/internal/model"
+)
+
+type ProblemKind string
+
+const (
+	ForeignChar    ProblemKind = "foreign-char"
+	OddLength      ProblemKind = "odd-length"
+	DoubledDigraph ProblemKind = "doubled-digraph"
+	// StraySeparator marks a deciphered filler char where encryption could
+	// not have inserted it, so it has to be part of the text.
+	StraySeparator ProblemKind = "stray-separator"
+)
+
+// Problem is something in a ciphertext this Engine could not have produced,
+// found at rune Offset.
+type Problem struct {
+	Offset  int         `json:"offset"`
+	Kind    ProblemKind `json:"kind"`
+	Message string      `json:"message"`
+}
+
+// Validate scans the whole cipherText and returns every problem found, in
+// text order. Runes missing from the matrices are problems unless
+// Config.PassThrough is set. A stray separator is no proof, the plaintext may
+// contain the separator char, but it is rare with a rare separator.
+func (e *Engine) Validate(cipherText string) []Problem {
+	problems := make([]Problem, 0)
+
+	var (
+		chars   []rune
+		offsets []int
+	)
+	for offset, char := range []rune(cipherText) {
+		switch {
+		case e.has(char):
+			chars = append(chars, char)
+			offsets = append(offsets, offset)
+		case !e.cfg.PassThrough:
+			problems = append(problems, Problem{
+				Offset:  offset,
+				Kind:    ForeignChar,
+				Message: fmt.Sprintf("char '%c' not found in grid", char),
+			})
+		}
+	}
+
+	size := 2
+	if e.cfg.Algorithm == model.Cube {
+		size = 3
+	}
+
+	if n := len(chars); n%size != 0 {
+		problems = append(problems, Problem{
+			Offset:  offsets[n-1],
+			Kind:    OddLength,
+			Message: fmt.Sprintf("%d chars of the grid don't split into groups of %d", n, size),
+		})
+	}
+
+	// only Playfair splits doubled chars, other ciphers may have them on
+	// either side
+	if e.cfg.Algorithm != model.Playfair {
+		return sortProblems(problems)
+	}
+
+	plain := make([]rune, len(chars)-len(chars)%2)
+	for k := 0; k < len(plain); k += 2 {
+		pair := chars[k : k+2]
+		if pair[0] == pair[1] {
+			problems = append(problems, Problem{
+				Offset:  offsets[k],
+				Kind:    DoubledDigraph,
+				Message: fmt.Sprintf("doubled digraph '%c%c'", pair[0], pair[1]),
+			})
+			continue
+		}
+
+		if res, err := e.decryptPair(pair); err == nil {
+			copy(plain[k:], res)
+		}
+	}
+
+	for i, char := range plain {
+		if char != 0 && e.isFiller(char) && !e.fillerFits(plain, i) {
+			problems = append(problems, Problem{
+				Offset:  offsets[i],
+				Kind:    StraySeparator,
+				Message: fmt.Sprintf("'%c' deciphers to separator '%c' where no filler is inserted", chars[i], char),
+			})
+		}
+	}
+
+	return sortProblems(problems)
+}
+
+func (e *Engine) isFiller(char rune) bool {
+	return char == e.fillers.Double || char == e.fillers.Pad || char == e.fillers.Alt
+}
+
+// fillerFits reports whether getPairs could have inserted plain[i]: between
+// two equal chars of a pair, as the tail marker or as the padding of the
+// last pair.
+func (e *Engine) fillerFits(plain []rune, i int) bool {
+	n := len(plain)
+	if marker := e.fillers.TailMarker(); marker != 0 && plain[i] == marker &&
+		(i == n-1 || i == n-2 && plain[n-1] == e.fillers.PadFor(marker)) {
+		return true
+	}
+
+	switch {
+	case i%2 == 0:
+		return false
+	case i == n-1:
+		return plain[i] == e.fillers.PadFor(plain[i-1])
+	}
+
+	return plain[i-1] == plain[i+1] && plain[i] == e.fillers.DoubleFor(plain[i-1])
+}
+
+// decryptPair deciphers one digraph through every round, keeping the
+// fillers.
+func (e *Engine) decryptPair(pair []rune) ([]rune, error) {
+	res := string(pair)
+	for i := len(e.rounds) - 1; i >= 0; i-- {
+		var err error
+		if res, err = e.rounds[i].dec.Decode(res, model.Fillers{}); err != nil {
+			return nil, err
+		}
+	}
+
+	return []rune(res), nil
+}
+
+// sortProblems orders problems by offset, keeping the scan order of the
+// problems found at one offset.
+func sortProblems(problems []Problem) []Problem {
+	slices.SortStableFunc(problems, func(a, b Problem) int {
+		return a.Offset - b.Offset
+	})
+
+	return problems
+}